@@ -0,0 +1,296 @@
+// Command prbctl inspects and edits persisted buffers: binary or JSON
+// snapshots and write-ahead logs.
+//
+// Usage:
+//
+//	prbctl [flags] stats FILE
+//	prbctl [flags] list [-priority N] [-min-priority N] FILE
+//	prbctl [flags] remove -o OUT INDEX... FILE
+//	prbctl [flags] insert -o OUT -priority N FILE VALUE
+//	prbctl [flags] repair -o OUT [-resort] FILE
+//
+// Snapshots are recognised by content; pass -wal to read a write-ahead log,
+// which is never modified. Edited buffers are written as binary snapshots,
+// or JSON with -json. Encrypted files need their AES key, given in hex with
+// -key or raw in the file named by -keyfile; binary output is then
+// encrypted with it as well.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"GoPRB/prb"
+	// Registers zstd so compressed files using it can be read.
+	_ "GoPRB/prbzstd"
+)
+
+type options struct {
+	wal      bool
+	capacity int
+	asJSON   bool
+	partial  bool
+	key      []byte
+}
+
+func main() {
+	var opts options
+	valueType := flag.String("type", "string", "element value type: string, int or float")
+	flag.BoolVar(&opts.wal, "wal", false, "read FILE as a write-ahead log")
+	flag.IntVar(&opts.capacity, "capacity", 1, "capacity to assume for a write-ahead log without a state record")
+	flag.BoolVar(&opts.asJSON, "json", false, "write JSON instead of binary snapshots")
+	flag.BoolVar(&opts.partial, "partial", false, "restore what is left of a corrupt FILE, skipping bad records")
+	key := flag.String("key", "", "hex encoded AES key of an encrypted FILE")
+	keyFile := flag.String("keyfile", "", "file holding the raw AES key of an encrypted FILE")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: prbctl [flags] stats|list|remove|insert|repair [command flags] FILE")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch {
+	case *key != "" && *keyFile != "":
+		err = errors.New("-key and -keyfile are mutually exclusive")
+	case *key != "":
+		opts.key, err = hex.DecodeString(*key)
+	case *keyFile != "":
+		opts.key, err = os.ReadFile(*keyFile)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "prbctl:", err)
+		os.Exit(2)
+	}
+
+	switch *valueType {
+	case "string":
+		err = run(opts, flag.Args(), func(s string) (string, error) { return s, nil })
+	case "int":
+		err = run(opts, flag.Args(), func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+	case "float":
+		err = run(opts, flag.Args(), func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+	default:
+		err = fmt.Errorf("unknown value type %q", *valueType)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "prbctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run[T comparable](opts options, args []string, parse func(string) (T, error)) error {
+	command, args := args[0], args[1:]
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	out := fs.String("o", "", "output file")
+
+	switch command {
+	case "stats":
+		fs.Parse(args)
+		b, err := load[T](opts, fs.Args())
+		if err != nil {
+			return err
+		}
+		return printStats(b)
+
+	case "list":
+		priority := fs.String("priority", "", "only list elements with this priority")
+		minPriority := fs.String("min-priority", "", "only list elements with at least this priority")
+		fs.Parse(args)
+
+		var filters []prb.SearchFilter[T]
+		if *priority != "" {
+			p, err := strconv.Atoi(*priority)
+			if err != nil {
+				return err
+			}
+			filters = append(filters, prb.SearchByPriority[T](p))
+		}
+		if *minPriority != "" {
+			p, err := strconv.Atoi(*minPriority)
+			if err != nil {
+				return err
+			}
+			filters = append(filters, prb.SearchByMinPriority[T](p))
+		}
+
+		b, err := load[T](opts, fs.Args())
+		if err != nil {
+			return err
+		}
+		return printElements(b, filters)
+
+	case "remove":
+		fs.Parse(args)
+		if fs.NArg() < 2 {
+			return errors.New("remove needs at least one index and a file")
+		}
+
+		b, err := load[T](opts, fs.Args()[fs.NArg()-1:])
+		if err != nil {
+			return err
+		}
+
+		var indexes []int
+		for _, arg := range fs.Args()[:fs.NArg()-1] {
+			i, err := strconv.Atoi(arg)
+			if err != nil {
+				return err
+			}
+			indexes = append(indexes, i)
+		}
+
+		// Remove from the back so earlier indexes stay valid.
+		slices.Sort(indexes)
+		indexes = slices.Compact(indexes)
+		slices.Reverse(indexes)
+		for _, i := range indexes {
+			if _, err := b.RemoveAt(i); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return save(opts, b, *out)
+
+	case "insert":
+		priority := fs.Int("priority", 0, "priority of the inserted element")
+		fs.Parse(args)
+		if fs.NArg() != 2 {
+			return errors.New("insert needs a file and a value")
+		}
+
+		b, err := load[T](opts, fs.Args()[:1])
+		if err != nil {
+			return err
+		}
+		value, err := parse(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		if err := b.Insert(value, *priority); err != nil {
+			return err
+		}
+		return save(opts, b, *out)
+
+	case "repair":
+		resort := fs.Bool("resort", false, "restore priority order within the bubble window")
+		fs.Parse(args)
+
+		b, err := load[T](opts, fs.Args())
+		if err != nil {
+			return err
+		}
+		if *resort {
+			if err := b.Compact(true); err != nil {
+				return err
+			}
+		}
+		if err := b.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, "prbctl: warning:", err)
+		}
+		return save(opts, b, *out)
+	}
+
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// load reads the single file in args as a snapshot or, with -wal, a log.
+// A torn record at the end of a log is skipped, and with -partial so are
+// corrupt records, with a warning.
+func load[T comparable](opts options, args []string) (*prb.PriorityRingBuffer[T], error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected exactly one file")
+	}
+
+	var bufferOpts []prb.Option[T]
+	if opts.key != nil {
+		bufferOpts = append(bufferOpts, prb.WithEncryptionKey[T](opts.key))
+	}
+	if opts.partial {
+		bufferOpts = append(bufferOpts, prb.WithPartialRestore[T](func(err *prb.CorruptionError) {
+			fmt.Fprintln(os.Stderr, "prbctl: warning: skipped", err)
+		}))
+	}
+
+	if opts.wal {
+		return prb.ReplayWAL[T](args[0], opts.capacity, bufferOpts...)
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := prb.New[T](1, bufferOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if json.Valid(data) {
+		err = b.UnmarshalJSON(data)
+	} else {
+		err = b.UnmarshalBinary(data)
+	}
+	return b, err
+}
+
+func save[T comparable](opts options, b *prb.PriorityRingBuffer[T], path string) error {
+	if path == "" {
+		return errors.New("missing -o output file")
+	}
+
+	var data []byte
+	var err error
+	if opts.asJSON {
+		data, err = b.MarshalJSON()
+	} else {
+		data, err = b.MarshalBinary()
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func printStats[T comparable](b *prb.PriorityRingBuffer[T]) error {
+	stats := b.GetStats()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "size\t%d\n", stats.Size)
+	fmt.Fprintf(w, "capacity\t%d\n", stats.Capacity)
+	fmt.Fprintf(w, "bubble window\t%d\n", stats.BubbleWindow)
+	fmt.Fprintf(w, "overflow mode\t%v\n", b.OverflowMode())
+	fmt.Fprintf(w, "order counter\t%d\n", stats.OrderCounter)
+	for _, q := range b.Quotas() {
+		fmt.Fprintf(w, "quota\t[%d, %d] limit %d\n", q.MinPriority, q.MaxPriority, q.Limit)
+	}
+	if highest, err := b.MaxPriority(); err == nil {
+		lowest, _ := b.MinPriority()
+		fmt.Fprintf(w, "priorities\t%d to %d\n", lowest, highest)
+	}
+	if err := b.Validate(); err != nil {
+		fmt.Fprintf(w, "invalid\t%v\n", err)
+	}
+	return w.Flush()
+}
+
+func printElements[T comparable](b *prb.PriorityRingBuffer[T], filters []prb.SearchFilter[T]) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tPRIORITY\tORDER\tKEY\tVALUE")
+	b.SearchFunc(func(i int, e prb.Element[T]) bool {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%v\n", i, e.Priority, e.InsertionOrder, e.Key, e.Value)
+		return true
+	}, filters...)
+	return w.Flush()
+}
@@ -0,0 +1,181 @@
+package prb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrUnknownDelivery = errors.New("no in-flight delivery with this id")
+
+// Delivery is an element handed out by DequeueDelivery that stays in flight
+// until it is acknowledged.
+type Delivery[T comparable] struct {
+	ID      uint64
+	Element Element[T]
+	// Attempts counts deliveries of the element, starting at one.
+	Attempts int
+}
+
+type inflight[T comparable] struct {
+	element  Element[T]
+	attempts int
+	stop     chan struct{}
+}
+
+// WithAckTimeout makes DequeueDelivery redeliver elements at their original
+// priority if they are neither acknowledged nor rejected within timeout.
+func WithAckTimeout[T comparable](timeout time.Duration) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.ackTimeout = timeout
+	}
+}
+
+// DequeueDelivery removes the head element like Dequeue but keeps it in
+// flight until Ack or Nack is called with the delivery's ID, or the ack
+// timeout reinserts it. In-flight elements are not part of snapshots or the
+// write-ahead log and are lost when the buffer is closed.
+func (b *PriorityRingBuffer[T]) DequeueDelivery() (Delivery[T], error) {
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Delivery[T]{}, ErrClosed
+	}
+
+	if b.size == 0 {
+		return Delivery[T]{}, ErrBufferEmpty
+	}
+
+	return b.deliver()
+}
+
+// DequeueDeliveryContext is like DequeueDelivery but waits for an element
+// while the buffer is empty. It returns ctx.Err() if ctx is done first.
+func (b *PriorityRingBuffer[T]) DequeueDeliveryContext(ctx context.Context) (Delivery[T], error) {
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Delivery[T]{}, ErrClosed
+	}
+
+	ready := func() bool { return b.size > 0 }
+	if err := b.wait(ready, nil, ctx.Done()); err != nil {
+		return Delivery[T]{}, err
+	}
+	if b.size == 0 {
+		return Delivery[T]{}, ctx.Err()
+	}
+
+	return b.deliver()
+}
+
+// deliver dequeues the head element as a new in-flight delivery. The buffer
+// must not be empty and the caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) deliver() (Delivery[T], error) {
+	element, err := b.dequeue()
+	if err != nil {
+		return Delivery[T]{}, err
+	}
+
+	b.deliveryID++
+	d := Delivery[T]{
+		ID:       b.deliveryID,
+		Element:  element,
+		Attempts: element.attempts + 1,
+	}
+
+	f := &inflight[T]{element: element, attempts: d.Attempts}
+	if b.ackTimeout > 0 {
+		f.stop = make(chan struct{})
+		b.nackAfter(d.ID, b.ackTimeout, f.stop)
+	}
+
+	if b.deliveries == nil {
+		b.deliveries = make(map[uint64]*inflight[T])
+	}
+	b.deliveries[d.ID] = f
+
+	return d, nil
+}
+
+// Ack completes the delivery with id, discarding its element.
+func (b *PriorityRingBuffer[T]) Ack(id uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, err := b.settle(id)
+	return err
+}
+
+// Nack reinserts the element of the delivery with id with its priority
+// raised by boost, keeping its key and tags, unless it has reached
+// WithMaxAttempts, in which case it goes to the dead letter. A keyed element is not
+// reinserted if a newer element with its key has been queued since. With
+// decay, the element is reinserted at the priority it was delivered with
+// and starts aging afresh.
+func (b *PriorityRingBuffer[T]) Nack(id uint64, boost int) error {
+	b.mu.Lock()
+	f, err := b.settle(id)
+	stale := err == nil && b.keySlot(f.element.Key) >= 0
+	b.mu.Unlock()
+
+	if err != nil || stale {
+		return err
+	}
+
+	if b.maxAttempts > 0 && f.attempts >= b.maxAttempts {
+		if b.deadLetter != nil {
+			b.deadLetter(f.element, ErrMaxAttempts)
+		}
+		return nil
+	}
+
+	element := f.element
+	element.Priority += boost
+	element.GuaranteedMax = false
+	element.inserted = time.Time{}
+	element.attempts = f.attempts
+
+	_, _, err = b.insert(element, nil, nil)
+	return err
+}
+
+// InFlight returns the number of deliveries awaiting Ack or Nack.
+func (b *PriorityRingBuffer[T]) InFlight() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.deliveries)
+}
+
+// nackAfter calls Nack for id once delay has passed, unless stop is closed
+// first.
+func (b *PriorityRingBuffer[T]) nackAfter(id uint64, delay time.Duration, stop chan struct{}) {
+	timer := b.clock.NewTimer(delay)
+	b.goLabelled("ack-timeout", func() {
+		select {
+		case <-timer.C():
+			b.Nack(id, 0)
+		case <-stop:
+			timer.Stop()
+		}
+	})
+}
+
+// settle removes the delivery with id from the in-flight set and stops its
+// timeout. The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) settle(id uint64) (*inflight[T], error) {
+	f, ok := b.deliveries[id]
+	if !ok {
+		return nil, ErrUnknownDelivery
+	}
+
+	delete(b.deliveries, id)
+	if f.stop != nil {
+		close(f.stop)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,62 @@
+package prb
+
+// adaptivePeriod is the number of inserts between window adjustments.
+const adaptivePeriod = 64
+
+type adaptiveWindow struct {
+	min       int
+	max       int
+	inserts   int
+	truncated int
+}
+
+// WithAdaptiveWindow lets the buffer tune its bubble window within
+// [minWindow, maxWindow]. After every adaptivePeriod inserts the window
+// doubles if more than a tenth of them were cut short by it, and shrinks by
+// a quarter if none were. SetBubbleWindow still works and adaptation
+// continues from the new value.
+func WithAdaptiveWindow[T comparable](minWindow, maxWindow int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.adaptive = &adaptiveWindow{min: minWindow, max: maxWindow}
+	}
+}
+
+// fit cuts the window range to what a buffer with capacity allows.
+func (a *adaptiveWindow) fit(capacity int) {
+	a.max = min(a.max, capacity-1)
+	a.min = min(a.min, a.max)
+}
+
+// adaptWindow records whether an insert was truncated and adjusts the window
+// at the end of a period. Changes are logged like SetBubbleWindow so replay
+// does not depend on the adaptation state.
+func (b *PriorityRingBuffer[T]) adaptWindow(truncated bool) {
+	a := b.adaptive
+	if a == nil {
+		return
+	}
+
+	a.inserts++
+	if truncated {
+		a.truncated++
+	}
+	if a.inserts < adaptivePeriod {
+		return
+	}
+
+	window := b.bubbleWindow
+	switch {
+	case a.truncated*10 > a.inserts:
+		window = max(window*2, window+1)
+	case a.truncated == 0:
+		window -= window / 4
+	}
+	window = min(max(window, a.min), a.max)
+	a.inserts, a.truncated = 0, 0
+
+	if window == b.bubbleWindow || b.logSetBubbleWindow(window) != nil {
+		return
+	}
+	b.bubbleWindow = window
+	b.setLayout()
+}
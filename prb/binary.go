@@ -0,0 +1,510 @@
+package prb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+)
+
+var (
+	ErrInvalidFormat      = errors.New("data is not a binary encoded buffer")
+	ErrUnsupportedVersion = errors.New("unsupported binary format version")
+)
+
+const (
+	binaryMagic   = "GPRB"
+	binaryVersion = 2
+
+	// binaryTagged is set in the flags byte when every element is followed
+	// by its tags.
+	binaryTagged = 1 << 0
+	// binaryKeyed is set when every element is followed by its key.
+	binaryKeyed = 1 << 1
+)
+
+// Codec encodes element values for the binary format. DecodeValue receives
+// exactly the bytes produced by one AppendValue call.
+type Codec[T any] interface {
+	AppendValue(dst []byte, v T) ([]byte, error)
+	DecodeValue(src []byte) (T, error)
+}
+
+// WithCodec sets the value codec used by MarshalBinary and UnmarshalBinary.
+func WithCodec[T comparable](codec Codec[T]) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.codec = codec
+	}
+}
+
+// DefaultCodec encodes strings, booleans and numeric kinds directly and falls
+// back to gob for everything else.
+type DefaultCodec[T any] struct{}
+
+func (DefaultCodec[T]) AppendValue(dst []byte, v T) ([]byte, error) {
+	switch x := any(v).(type) {
+	case string:
+		return append(dst, x...), nil
+	case bool:
+		if x {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case int:
+		return binary.AppendVarint(dst, int64(x)), nil
+	case int8:
+		return binary.AppendVarint(dst, int64(x)), nil
+	case int16:
+		return binary.AppendVarint(dst, int64(x)), nil
+	case int32:
+		return binary.AppendVarint(dst, int64(x)), nil
+	case int64:
+		return binary.AppendVarint(dst, x), nil
+	case uint:
+		return binary.AppendUvarint(dst, uint64(x)), nil
+	case uint8:
+		return append(dst, x), nil
+	case uint16:
+		return binary.AppendUvarint(dst, uint64(x)), nil
+	case uint32:
+		return binary.AppendUvarint(dst, uint64(x)), nil
+	case uint64:
+		return binary.AppendUvarint(dst, x), nil
+	case float32:
+		return binary.LittleEndian.AppendUint32(dst, math.Float32bits(x)), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(x)), nil
+	}
+
+	return GobCodec[T]{}.AppendValue(dst, v)
+}
+
+func (DefaultCodec[T]) DecodeValue(src []byte) (T, error) {
+	var v T
+	var err error
+
+	d := decoder{data: src}
+	switch p := any(&v).(type) {
+	case *string:
+		*p = string(src)
+	case *bool:
+		*p = d.byte() != 0
+	case *int:
+		*p = int(d.varint())
+	case *int8:
+		*p = int8(d.varint())
+	case *int16:
+		*p = int16(d.varint())
+	case *int32:
+		*p = int32(d.varint())
+	case *int64:
+		*p = d.varint()
+	case *uint:
+		*p = uint(d.uvarint())
+	case *uint8:
+		*p = d.byte()
+	case *uint16:
+		*p = uint16(d.uvarint())
+	case *uint32:
+		*p = uint32(d.uvarint())
+	case *uint64:
+		*p = d.uvarint()
+	case *float32:
+		*p = math.Float32frombits(binary.LittleEndian.Uint32(d.bytes(4)))
+	case *float64:
+		*p = math.Float64frombits(binary.LittleEndian.Uint64(d.bytes(8)))
+	default:
+		v, err = GobCodec[T]{}.DecodeValue(src)
+	}
+
+	if d.err != nil {
+		return v, d.err
+	}
+
+	return v, err
+}
+
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) AppendValue(dst []byte, v T) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	if err := gob.NewEncoder(buf).Encode(&v); err != nil {
+		return dst, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (GobCodec[T]) DecodeValue(src []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(src)).Decode(&v)
+	return v, err
+}
+
+func (b *PriorityRingBuffer[T]) valueCodec() Codec[T] {
+	if b.codec != nil {
+		return b.codec
+	}
+
+	return DefaultCodec[T]{}
+}
+
+func (b *PriorityRingBuffer[T]) MarshalBinary() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	data, err := appendState(nil, b.state(), b.valueCodec())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = compressState(data, b.compressor)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryptState(data, b.keyProvider)
+}
+
+func (b *PriorityRingBuffer[T]) UnmarshalBinary(data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, err := b.decryptState(data)
+	if err == nil {
+		data, err = b.decompressState(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	s, err := decodeState(data, b.valueCodec(), b.onCorrupt())
+	if err != nil {
+		return err
+	}
+
+	return b.load(s)
+}
+
+// appendState writes the snapshot in the current format: the header ends
+// with its checksum, every element is a length-prefixed record followed by
+// its checksum, and a checksum of everything before it closes the snapshot.
+func appendState[T comparable](dst []byte, s Snapshot[T], codec Codec[T]) ([]byte, error) {
+	var flags byte
+	for _, e := range s.Elements {
+		if len(e.Tags) > 0 {
+			flags |= binaryTagged
+		}
+		if e.Key != "" {
+			flags |= binaryKeyed
+		}
+	}
+
+	start := len(dst)
+	dst = append(dst, binaryMagic...)
+	dst = append(dst, binaryVersion, flags)
+
+	dst = binary.AppendUvarint(dst, uint64(s.Capacity))
+	dst = binary.AppendUvarint(dst, uint64(s.BubbleWindow))
+	dst = append(dst, byte(s.OverflowMode))
+	dst = binary.AppendVarint(dst, s.OrderCounter)
+
+	dst = binary.AppendUvarint(dst, uint64(len(s.Quotas)))
+	for _, q := range s.Quotas {
+		dst = binary.AppendVarint(dst, int64(q.MinPriority))
+		dst = binary.AppendVarint(dst, int64(q.MaxPriority))
+		dst = binary.AppendUvarint(dst, uint64(q.Limit))
+	}
+
+	dst = binary.AppendUvarint(dst, uint64(len(s.Elements)))
+	dst = appendChecksum(dst, dst[start:])
+
+	var record []byte
+	for _, e := range s.Elements {
+		var err error
+		if record, err = appendElement(record[:0], e, codec); err != nil {
+			return nil, err
+		}
+		if flags&binaryKeyed != 0 {
+			record = appendString(record, e.Key)
+		}
+		if flags&binaryTagged != 0 {
+			record = appendTags(record, e.Tags)
+		}
+
+		dst = binary.AppendUvarint(dst, uint64(len(record)))
+		dst = append(dst, record...)
+		dst = appendChecksum(dst, record)
+	}
+
+	return appendChecksum(dst, dst[start:]), nil
+}
+
+func appendElement[T comparable](dst []byte, e Element[T], codec Codec[T]) ([]byte, error) {
+	dst = binary.AppendVarint(dst, int64(e.Priority))
+	dst = binary.AppendVarint(dst, e.InsertionOrder)
+
+	value, err := codec.AppendValue(nil, e.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	dst = binary.AppendUvarint(dst, uint64(len(value)))
+	return append(dst, value...), nil
+}
+
+// appendTags writes tags as a count followed by length-prefixed keys and
+// values, sorted by key so equal maps encode identically.
+func appendTags(dst []byte, tags map[string]string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(tags)))
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		dst = appendString(dst, k)
+		dst = appendString(dst, tags[k])
+	}
+
+	return dst
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func appendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 1)
+	}
+
+	return append(dst, 0)
+}
+
+// decodeState reads a snapshot in the current or the first format, which
+// has no checksums. With onCorrupt set, bad element records are reported to
+// it and skipped instead of failing the decode.
+func decodeState[T comparable](data []byte, codec Codec[T], onCorrupt func(*CorruptionError)) (Snapshot[T], error) {
+	var s Snapshot[T]
+
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) || len(data) < len(binaryMagic)+2 {
+		return s, ErrInvalidFormat
+	}
+
+	d := decoder{data: data[len(binaryMagic):]}
+	version := d.byte()
+	if version != 1 && version != binaryVersion {
+		return s, ErrUnsupportedVersion
+	}
+	flags := d.byte()
+
+	s.Capacity = int(d.uvarint())
+	s.BubbleWindow = int(d.uvarint())
+	s.OverflowMode = OverflowMode(d.byte())
+	s.OrderCounter = d.varint()
+
+	quotas := d.count()
+	for i := 0; i < quotas && d.err == nil; i++ {
+		s.Quotas = append(s.Quotas, Quota{
+			MinPriority: int(d.varint()),
+			MaxPriority: int(d.varint()),
+			Limit:       int(d.uvarint()),
+		})
+	}
+
+	elements := d.count()
+	offset := func() int { return len(data) - len(d.data) }
+
+	decodeEntry := func(d *decoder) (Element[T], error) {
+		e, err := decodeElement(d, codec)
+		if err != nil {
+			return e, err
+		}
+		if flags&binaryKeyed != 0 {
+			e.Key = d.string()
+		}
+		if flags&binaryTagged != 0 {
+			e.Tags = d.tags()
+		}
+		return e, d.err
+	}
+
+	if version == 1 {
+		for i := 0; i < elements && d.err == nil; i++ {
+			e, err := decodeEntry(&d)
+			if err != nil {
+				return s, err
+			}
+			s.Elements = append(s.Elements, e)
+		}
+
+		if d.err != nil {
+			return s, d.err
+		}
+
+		return s, nil
+	}
+
+	header := offset()
+	if sum := d.bytes(checksumLen); d.err != nil || !checksumValid(data[:header], sum) {
+		return s, &CorruptionError{Offset: 0, Err: ErrChecksum}
+	}
+
+	// Once a record is reported the closing checksum is known to be bad.
+	corrupted := false
+	for i := 0; i < elements; i++ {
+		start := offset()
+		record := d.bytes(d.count())
+		sum := d.bytes(checksumLen)
+		if d.err != nil {
+			// The framing is lost, so no later record can be found.
+			err := &CorruptionError{Offset: int64(start), Err: ErrInvalidFormat}
+			if onCorrupt == nil {
+				return s, err
+			}
+			onCorrupt(err)
+			return s, nil
+		}
+
+		if !checksumValid(record, sum) {
+			err := &CorruptionError{Offset: int64(start), Err: ErrChecksum}
+			if onCorrupt == nil {
+				return s, err
+			}
+			onCorrupt(err)
+			corrupted = true
+			continue
+		}
+
+		rd := decoder{data: record}
+		e, err := decodeEntry(&rd)
+		if err != nil {
+			return s, err
+		}
+		s.Elements = append(s.Elements, e)
+	}
+
+	end := offset()
+	sum := d.bytes(checksumLen)
+	var err *CorruptionError
+	switch {
+	case d.err != nil || len(d.data) > 0:
+		err = &CorruptionError{Offset: int64(end), Err: ErrInvalidFormat}
+	case !corrupted && !checksumValid(data[:end], sum):
+		err = &CorruptionError{Offset: int64(end), Err: ErrChecksum}
+	}
+	if err != nil {
+		if onCorrupt == nil {
+			return s, err
+		}
+		onCorrupt(err)
+	}
+
+	return s, nil
+}
+
+func decodeElement[T comparable](d *decoder, codec Codec[T]) (Element[T], error) {
+	e := Element[T]{
+		Priority:       int(d.varint()),
+		InsertionOrder: d.varint(),
+	}
+
+	value := d.bytes(d.count())
+	if d.err != nil {
+		return e, d.err
+	}
+
+	var err error
+	e.Value, err = codec.DecodeValue(value)
+	return e, err
+}
+
+// decoder reads varint-framed fields, recording the first error and
+// returning zero values afterwards.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidFormat
+	}
+	d.data = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.data) < 1 {
+		d.fail()
+		return 0
+	}
+
+	v := d.data[0]
+	d.data = d.data[1:]
+	return v
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if n < 0 || len(d.data) < n {
+		d.fail()
+		return nil
+	}
+
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.data = d.data[n:]
+	return v
+}
+
+// string reads what appendString wrote.
+func (d *decoder) string() string {
+	return string(d.bytes(d.count()))
+}
+
+// tags reads what appendTags wrote, returning nil for an empty set.
+func (d *decoder) tags() map[string]string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		k := d.string()
+		tags[k] = d.string()
+	}
+
+	return tags
+}
+
+// count reads a length that must fit in the remaining input.
+func (d *decoder) count() int {
+	v := d.uvarint()
+	if v > uint64(len(d.data)) {
+		d.fail()
+		return 0
+	}
+
+	return int(v)
+}
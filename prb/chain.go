@@ -0,0 +1,68 @@
+package prb
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidOverflowBuffer = errors.New("overflow buffer must be another buffer that does not block")
+	ErrChainedLog            = errors.New("buffers with an overflow buffer cannot keep a write-ahead log or be replicated")
+)
+
+// WithOverflowBuffer chains a secondary buffer, for example a larger one
+// for low priority spill: inserts into a full buffer go to secondary, and
+// only if it rejects them does the overflow mode apply. Whenever the buffer
+// has room after a change, and when Dequeue finds it empty, it moves the
+// highest priority elements of secondary into the ring, so dequeues see the
+// best elements of both, keeping their insertion order. secondary must not
+// use Block, chains must not loop, and elements in secondary are not part of
+// snapshots of this buffer. Moves between the buffers cannot be replayed, so
+// OpenFromWAL, ReplayWAL, Replicator and FollowStream fail with
+// ErrChainedLog for a buffer with an overflow buffer; secondary itself may
+// keep a log.
+func WithOverflowBuffer[T comparable](secondary *PriorityRingBuffer[T]) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.secondary = secondary
+	}
+}
+
+// validSecondary reports whether the overflow buffer, if any, can be used.
+func (b *PriorityRingBuffer[T]) validSecondary() bool {
+	return b.secondary == nil || (b.secondary != b && b.secondary.OverflowMode() != Block)
+}
+
+// overflowTo moves element into the overflow buffer and reports whether it
+// took it. The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) overflowTo(element Element[T]) bool {
+	if b.secondary == nil {
+		return false
+	}
+
+	element.keepOrder = true
+	_, _, err := b.secondary.insert(element, nil, nil)
+	return err == nil
+}
+
+// pullSecondary moves the best elements of the overflow buffer into the ring
+// while there is room. The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) pullSecondary() {
+	for b.secondary != nil && b.size < b.capacity {
+		element, ok := b.secondary.TryDequeue()
+		if !ok {
+			return
+		}
+
+		// A newer element with the same key was inserted here since.
+		if b.keySlot(element.Key) >= 0 {
+			continue
+		}
+
+		element.GuaranteedMax = false
+		element.inserted = time.Time{}
+		b.orderCounter = max(b.orderCounter, element.InsertionOrder+1)
+		if _, _, err := b.push(element); err != nil {
+			b.bury(element, err)
+		}
+	}
+}
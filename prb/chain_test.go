@@ -0,0 +1,84 @@
+package prb_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestOverflowBuffer(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []int
+		want       []int
+	}{
+		{"fits", []int{1, 2}, []int{1, 0}},
+		{"overflows", []int{1, 2, 3, 4}, []int{1, 3, 2, 0}},
+		{"keeps insertion order", []int{5, 5, 5, 5}, []int{0, 1, 2, 3}},
+		{"secondary full", []int{1, 2, 3, 4, 5, 6}, []int{5, 3, 2, 0}},
+		{"secondary full equal priorities", []int{5, 5, 5, 5, 5}, []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secondary := prb.MustNew[int](2,
+				prb.WithBubbleWindow[int](1),
+				prb.WithOverflowMode[int](prb.DropNewest))
+			b := prb.MustNew[int](2,
+				prb.WithBubbleWindow[int](1),
+				prb.WithOverflowBuffer(secondary))
+
+			for i, p := range tt.priorities {
+				if err := b.Insert(i, p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got []int
+			for !b.IsEmpty() {
+				e, err := b.Dequeue()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, e.Value)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("dequeued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverflowBufferRejectsLogs(t *testing.T) {
+	chained := func() []prb.Option[int] {
+		return []prb.Option[int]{prb.WithOverflowBuffer(prb.MustNew[int](2))}
+	}
+
+	path := filepath.Join(t.TempDir(), "wal")
+	if _, err := prb.OpenFromWAL[int](path, 2, chained()...); !errors.Is(err, prb.ErrChainedLog) {
+		t.Fatalf("OpenFromWAL: %v, want ErrChainedLog", err)
+	}
+	if _, err := prb.ReplayWAL[int](path, 2, chained()...); !errors.Is(err, prb.ErrChainedLog) {
+		t.Fatalf("ReplayWAL: %v, want ErrChainedLog", err)
+	}
+
+	r := prb.NewReplicator(prb.MustNew[int](2, chained()...))
+	if err := r.Follow(prb.MustNew[int](2)); !errors.Is(err, prb.ErrChainedLog) {
+		t.Fatalf("Follow from a chained leader: %v, want ErrChainedLog", err)
+	}
+	if err := r.Stream(&bytes.Buffer{}); !errors.Is(err, prb.ErrChainedLog) {
+		t.Fatalf("Stream from a chained leader: %v, want ErrChainedLog", err)
+	}
+
+	r = prb.NewReplicator(prb.MustNew[int](2))
+	if err := r.Follow(prb.MustNew[int](2, chained()...)); !errors.Is(err, prb.ErrChainedLog) {
+		t.Fatalf("Follow by a chained buffer: %v, want ErrChainedLog", err)
+	}
+	if err := prb.FollowStream(&bytes.Buffer{}, prb.MustNew[int](2, chained()...)); !errors.Is(err, prb.ErrChainedLog) {
+		t.Fatalf("FollowStream: %v, want ErrChainedLog", err)
+	}
+}
@@ -0,0 +1,199 @@
+package prb
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+var ErrInvalidCheckpoint = errors.New("checkpointer needs a sink and a positive interval or mutation threshold")
+
+// Checkpointer writes binary snapshots of a buffer to a sink on an interval,
+// after a number of mutations, or both.
+type Checkpointer[T comparable] struct {
+	buffer    *PriorityRingBuffer[T]
+	sink      func() (io.WriteCloser, error)
+	interval  time.Duration
+	threshold uint64
+	onSuccess func(Snapshot[T])
+	onError   func(error)
+	watcher   chan struct{}
+	saved     uint64
+	mu        sync.Mutex
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type CheckpointOption[T comparable] func(*Checkpointer[T])
+
+func CheckpointEvery[T comparable](interval time.Duration) CheckpointOption[T] {
+	return func(c *Checkpointer[T]) {
+		c.interval = interval
+	}
+}
+
+func CheckpointAfter[T comparable](mutations int) CheckpointOption[T] {
+	return func(c *Checkpointer[T]) {
+		c.threshold = uint64(max(mutations, 0))
+	}
+}
+
+func OnCheckpointSuccess[T comparable](fn func(Snapshot[T])) CheckpointOption[T] {
+	return func(c *Checkpointer[T]) {
+		c.onSuccess = fn
+	}
+}
+
+func OnCheckpointError[T comparable](fn func(error)) CheckpointOption[T] {
+	return func(c *Checkpointer[T]) {
+		c.onError = fn
+	}
+}
+
+// NewCheckpointer starts checkpointing b. Every checkpoint opens a fresh
+// writer from sink and closes it once the snapshot is written.
+func NewCheckpointer[T comparable](b *PriorityRingBuffer[T], sink func() (io.WriteCloser, error), opts ...CheckpointOption[T]) (*Checkpointer[T], error) {
+	c := &Checkpointer[T]{
+		buffer: b,
+		sink:   sink,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if sink == nil || (c.interval <= 0 && c.threshold == 0) {
+		return nil, ErrInvalidCheckpoint
+	}
+
+	b.mu.RLock()
+	c.saved = b.version
+	b.mu.RUnlock()
+
+	if c.threshold > 0 {
+		c.watcher = b.watch()
+	}
+
+	b.goLabelled("checkpointer", c.run)
+	return c, nil
+}
+
+func (c *Checkpointer[T]) run() {
+	defer close(c.done)
+
+	var timer Timer
+	var tick <-chan time.Time
+	if c.interval > 0 {
+		timer = c.buffer.clock.NewTimer(c.interval)
+		defer timer.Stop()
+		tick = timer.C()
+	}
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-tick:
+			c.report(c.checkpoint(false))
+			timer.Reset(c.interval)
+		case <-c.watcher:
+			if c.pending() >= c.threshold {
+				c.report(c.checkpoint(false))
+			}
+		}
+	}
+}
+
+func (c *Checkpointer[T]) pending() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buffer.mu.RLock()
+	defer c.buffer.mu.RUnlock()
+
+	return c.buffer.version - c.saved
+}
+
+func (c *Checkpointer[T]) report(err error) {
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// Checkpoint writes a snapshot now, even if nothing changed.
+func (c *Checkpointer[T]) Checkpoint() error {
+	return c.checkpoint(true)
+}
+
+func (c *Checkpointer[T]) checkpoint(force bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buffer.mu.RLock()
+	version := c.buffer.version
+	snapshot := c.buffer.state()
+	codec := c.buffer.valueCodec()
+	compressor := c.buffer.compressor
+	keys := c.buffer.keyProvider
+	c.buffer.mu.RUnlock()
+
+	if !force && version == c.saved {
+		return nil
+	}
+
+	data, err := appendState(nil, snapshot, codec)
+	if err == nil {
+		data, err = compressState(data, compressor)
+	}
+	if err == nil {
+		data, err = encryptState(data, keys)
+	}
+	if err != nil {
+		return err
+	}
+
+	w, err := c.sink()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	c.saved = version
+	if c.onSuccess != nil {
+		c.onSuccess(snapshot)
+	}
+
+	return nil
+}
+
+// Stop halts background checkpointing and writes a final checkpoint if the
+// buffer changed since the last one.
+func (c *Checkpointer[T]) Stop() error {
+	stopped := false
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		stopped = true
+	})
+	<-c.done
+
+	if !stopped {
+		return nil
+	}
+
+	if c.watcher != nil {
+		c.buffer.unwatch(c.watcher)
+	}
+
+	return c.checkpoint(false)
+}
@@ -0,0 +1,79 @@
+package prb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+var ErrChecksum = errors.New("checksum mismatch")
+
+// checksumLen is the length of a CRC-32C checksum, stored little-endian.
+const checksumLen = 4
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// CorruptionError reports the first bad record of a binary snapshot or
+// write-ahead log. Offset is from the start of the log file, or of the
+// snapshot after decryption and decompression. Err is ErrChecksum or
+// ErrInvalidFormat, or for logs of encrypted buffers ErrUnencrypted or
+// ErrRecordSequence.
+type CorruptionError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("corrupt record at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *CorruptionError) Unwrap() error {
+	return e.Err
+}
+
+// WithPartialRestore makes UnmarshalBinary, ReplayWAL and OpenFromWAL
+// restore what they can from corrupt data instead of failing: snapshots
+// with an intact header skip elements whose records are bad, and logs are
+// replayed up to their first bad record, which OpenFromWAL then discards
+// along with the rest of the log. report, if not nil, receives every
+// corruption that was skipped.
+func WithPartialRestore[T comparable](report func(*CorruptionError)) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.partial = true
+		b.onCorruption = report
+	}
+}
+
+// onCorrupt returns the handler for corruption found while restoring, or nil
+// if restores are strict.
+func (b *PriorityRingBuffer[T]) onCorrupt() func(*CorruptionError) {
+	if !b.partial {
+		return nil
+	}
+
+	return func(err *CorruptionError) {
+		if b.onCorruption != nil {
+			b.onCorruption(err)
+		}
+	}
+}
+
+func appendChecksum(dst, data []byte) []byte {
+	return binary.LittleEndian.AppendUint32(dst, crc32.Checksum(data, castagnoli))
+}
+
+func checksumValid(data, sum []byte) bool {
+	return len(sum) == checksumLen && binary.LittleEndian.Uint32(sum) == crc32.Checksum(data, castagnoli)
+}
+
+// shiftCorruption moves the offset of a CorruptionError in err by delta, for
+// data nested at that offset.
+func shiftCorruption(err error, delta int) error {
+	var corrupt *CorruptionError
+	if errors.As(err, &corrupt) {
+		corrupt.Offset += int64(delta)
+	}
+
+	return err
+}
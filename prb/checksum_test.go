@@ -0,0 +1,145 @@
+package prb_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+// corrupt returns a copy of data with the first byte of the first occurrence
+// of marker flipped.
+func corrupt(t *testing.T, data []byte, marker string) []byte {
+	t.Helper()
+
+	i := bytes.Index(data, []byte(marker))
+	if i < 0 {
+		t.Fatalf("%q not found", marker)
+	}
+
+	data = bytes.Clone(data)
+	data[i] ^= 0xff
+	return data
+}
+
+func TestSnapshotCorruption(t *testing.T) {
+	source := prb.MustNew[string](4)
+	for i, v := range []string{"aaaa", "bbbb", "cccc"} {
+		if err := source.Insert(v, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := source.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		partial     bool
+		want        error
+		wantLen     int
+		wantReports int
+	}{
+		{"intact", data, false, nil, 3, 0},
+		{"bad element", corrupt(t, data, "bbbb"), false, prb.ErrChecksum, 0, 0},
+		{"bad element partial", corrupt(t, data, "bbbb"), true, nil, 2, 1},
+		{"bad magic partial", corrupt(t, data, "GPRB"), true, prb.ErrInvalidFormat, 0, 0},
+		{"truncated", data[:len(data)-6], false, prb.ErrInvalidFormat, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reports []*prb.CorruptionError
+			var opts []prb.Option[string]
+			if tt.partial {
+				opts = append(opts, prb.WithPartialRestore[string](func(err *prb.CorruptionError) {
+					reports = append(reports, err)
+				}))
+			}
+
+			b := prb.MustNew[string](4, opts...)
+			err := b.UnmarshalBinary(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("UnmarshalBinary: %v, want %v", err, tt.want)
+			}
+			var corruption *prb.CorruptionError
+			if errors.Is(tt.want, prb.ErrChecksum) && !errors.As(err, &corruption) {
+				t.Fatalf("UnmarshalBinary: %T, want *CorruptionError", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := b.Len(); got != tt.wantLen {
+				t.Fatalf("Len() = %d, want %d", got, tt.wantLen)
+			}
+			if len(reports) != tt.wantReports {
+				t.Fatalf("%d corruptions reported, want %d", len(reports), tt.wantReports)
+			}
+		})
+	}
+}
+
+func TestWALCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal")
+	b, err := prb.OpenFromWAL[string](path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []string{"aaaa", "bbbb", "cccc"} {
+		if err := b.Insert(v, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, corrupt(t, data, "bbbb"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = prb.ReplayWAL[string](path, 4)
+	var corruption *prb.CorruptionError
+	if !errors.As(err, &corruption) || !errors.Is(err, prb.ErrChecksum) {
+		t.Fatalf("ReplayWAL: %v, want a checksum *CorruptionError", err)
+	}
+
+	var reports []*prb.CorruptionError
+	b, err = prb.OpenFromWAL[string](path, 4, prb.WithPartialRestore[string](func(err *prb.CorruptionError) {
+		reports = append(reports, err)
+	}))
+	if err != nil {
+		t.Fatalf("OpenFromWAL: %v", err)
+	}
+	if len(reports) != 1 || reports[0].Offset != corruption.Offset {
+		t.Fatalf("reported %v, want one corruption at offset %d", reports, corruption.Offset)
+	}
+	if got := b.Len(); got != 1 {
+		t.Fatalf("Len() = %d, want 1", got)
+	}
+
+	if err := b.Insert("dddd", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err = prb.ReplayWAL[string](path, 4)
+	if err != nil {
+		t.Fatalf("ReplayWAL after recovery: %v", err)
+	}
+	if got := b.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,52 @@
+package prb
+
+import "time"
+
+// Clock is the source of time for everything time based in the buffer and
+// its helpers. Tests can substitute a manually advanced clock instead of
+// sleeping; the default uses the time package.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the part of *time.Timer a Clock has to provide.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+func WithClock[T comparable](clock Clock) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		if clock != nil {
+			b.clock = clock
+		}
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
@@ -0,0 +1,180 @@
+package prb
+
+import "slices"
+
+// Compact lays the live elements out contiguously from slot zero so they no
+// longer wrap around the backing array. With resort set, it also makes an
+// insertion pass in which every element may move up to bubbleWindow places,
+// repairing ordering that inserts left truncated.
+func (b *PriorityRingBuffer[T]) Compact(resort bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if err := b.logCompact(resort); err != nil {
+		return err
+	}
+
+	elements := b.state().Elements
+	if resort {
+		for i := 1; i < len(elements); i++ {
+			for j := i; j > max(i-b.bubbleWindow, 0) && outranks(elements[j], elements[j-1]); j-- {
+				elements[j], elements[j-1] = elements[j-1], elements[j]
+			}
+		}
+	}
+
+	b.relayout(elements)
+	b.commit()
+	return nil
+}
+
+// Reprioritize sets every element's priority to fn(element) and sorts the
+// contents into exact priority order, keeping insertion order among equal
+// priorities. It fails with ErrQuotaExceeded, leaving the buffer unchanged,
+// if the new priorities would overfill a quota.
+func (b *PriorityRingBuffer[T]) Reprioritize(fn func(Element[T]) int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	state := b.state()
+	used := make([]int, len(b.quotas))
+	for i := range state.Elements {
+		e := &state.Elements[i]
+		e.Priority = fn(*e)
+		for j, q := range b.quotas {
+			if q.covers(e.Priority) {
+				used[j]++
+				if used[j] > q.Limit {
+					return ErrQuotaExceeded
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(state.Elements, func(x, y Element[T]) int {
+		switch {
+		case outranks(x, y):
+			return -1
+		case outranks(y, x):
+			return 1
+		}
+		return 0
+	})
+
+	if b.logging() {
+		if err := b.logState(state); err != nil {
+			return err
+		}
+	}
+
+	b.relayout(state.Elements)
+	b.commit()
+	return nil
+}
+
+// PartitionBy atomically moves every element matching filter into a new
+// buffer built with the same options, preserving their relative order. The
+// new buffer does not inherit a write-ahead log, mapping, overflow buffer,
+// replicas or event stream.
+func (b *PriorityRingBuffer[T]) PartitionBy(filter SearchFilter[T]) (*PriorityRingBuffer[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	state := b.state()
+	var moved, kept []Element[T]
+	for _, e := range state.Elements {
+		if filter(e) {
+			moved = append(moved, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+
+	if b.logging() {
+		remaining := state
+		remaining.Elements = kept
+		if err := b.logState(remaining); err != nil {
+			return nil, err
+		}
+	}
+
+	partition := b.cloneConfig()
+	state.Elements = moved
+	if err := partition.load(state); err != nil {
+		return nil, err
+	}
+	if partition.viewBatch > 0 {
+		partition.publishView()
+	}
+
+	b.relayout(kept)
+	for _, e := range moved {
+		b.counters.removals++
+		b.emit(EventRemove, e, nil)
+	}
+
+	b.commit()
+	return partition, nil
+}
+
+// cloneConfig returns a buffer without storage configured with the options b
+// was built with, except for the overflow buffer, for load to fill. The
+// options were validated when b was built.
+func (b *PriorityRingBuffer[T]) cloneConfig() *PriorityRingBuffer[T] {
+	c := &PriorityRingBuffer[T]{clock: realClock{}, opts: b.opts}
+	for _, opt := range b.opts {
+		opt(c)
+	}
+	c.secondary = nil
+
+	return c
+}
+
+// relayout replaces the contents with elements, stored from slot zero, and
+// rebuilds every structure derived from them. The caller must hold the write
+// lock and commit afterwards.
+func (b *PriorityRingBuffer[T]) relayout(elements []Element[T]) {
+	for i := range b.quotas {
+		b.quotas[i].used = 0
+	}
+
+	b.unordered = false
+	for i, e := range elements {
+		b.elements[i] = e
+		if b.store != nil {
+			b.store.storeSlot(i, e)
+		}
+		if i > 0 && outranks(e, elements[i-1]) {
+			b.unordered = true
+		}
+		b.added(e)
+	}
+
+	b.head = 0
+	b.size = len(elements)
+	b.tail = b.wrap(b.size)
+	b.indexKeys()
+
+	b.cachedMax.Store(-1)
+	b.heapReset()
+	if b.sorted {
+		b.unordered = false
+		b.heapify()
+	} else {
+		for i := range elements {
+			b.heapAdd(i)
+		}
+	}
+}
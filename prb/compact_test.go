@@ -0,0 +1,75 @@
+package prb_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestPartitionByKeepsOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []prb.Option[string]
+		check func(t *testing.T, p *prb.PriorityRingBuffer[string])
+	}{
+		{"overflow mode", []prb.Option[string]{prb.WithOverflowMode[string](prb.DropNewest)}, func(t *testing.T, p *prb.PriorityRingBuffer[string]) {
+			if got := p.OverflowMode(); got != prb.DropNewest {
+				t.Fatalf("OverflowMode() = %v, want dropNewest", got)
+			}
+		}},
+		{"quota", []prb.Option[string]{prb.WithQuota[string](0, 9, 3)}, func(t *testing.T, p *prb.PriorityRingBuffer[string]) {
+			if got := p.Quotas(); len(got) != 1 || got[0].Limit != 3 {
+				t.Fatalf("Quotas() = %v, want one with limit 3", got)
+			}
+		}},
+		{"latency", []prb.Option[string]{prb.WithLatencyHistograms[string]()}, func(t *testing.T, p *prb.PriorityRingBuffer[string]) {
+			if p.GetStats().Latency == nil {
+				t.Fatal("no latency histograms")
+			}
+		}},
+		{"rate limit", []prb.Option[string]{prb.WithRateLimit[string](0.001, 3)}, func(t *testing.T, p *prb.PriorityRingBuffer[string]) {
+			for range 3 {
+				if err := p.Insert("c", 1); err != nil {
+					t.Fatalf("Insert within the burst: %v", err)
+				}
+			}
+			if err := p.Insert("c", 1); !errors.Is(err, prb.ErrRateLimited) {
+				t.Fatalf("Insert: %v, want ErrRateLimited", err)
+			}
+		}},
+		{"encryption", []prb.Option[string]{prb.WithEncryptionKey[string](key0)}, func(t *testing.T, p *prb.PriorityRingBuffer[string]) {
+			data, err := p.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(data, []byte("match")) {
+				t.Fatal("partition snapshot is not encrypted")
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := prb.MustNew[string](4, tt.opts...)
+			for _, v := range []string{"match", "keep", "match"} {
+				if err := b.Insert(v, 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			p, err := b.PartitionBy(func(e prb.Element[string]) bool { return e.Value == "match" })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Len() != 2 || b.Len() != 1 {
+				t.Fatalf("partition has %d elements and buffer %d, want 2 and 1", p.Len(), b.Len())
+			}
+			if err := p.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, p)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package prb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync"
+)
+
+var ErrUnknownCompressor = errors.New("data was compressed with an unregistered compressor")
+
+// Compressed snapshots are compressedMagic, the compressor ID and the
+// compressed binary snapshot.
+const compressedMagic = "GPRZ"
+
+// Compressor IDs used by this module. Other compressors should use IDs from
+// 16 up.
+const (
+	CompressorGzip byte = 1
+	CompressorZstd byte = 2
+)
+
+// walCompressMin is the smallest write-ahead log record worth compressing.
+const walCompressMin = 256
+
+// Compressor compresses binary snapshots and write-ahead log records. Its ID
+// is recorded with the compressed data so readers can pick the matching
+// compressor; it must be non-zero.
+type Compressor interface {
+	ID() byte
+	// Compress appends the compressed form of src to dst.
+	Compress(dst, src []byte) ([]byte, error)
+	Decompress(src []byte) ([]byte, error)
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[byte]Compressor{CompressorGzip: GzipCompressor{}}
+)
+
+// RegisterCompressor makes c available for reading data compressed with its
+// ID by buffers configured without it. Gzip is registered by default.
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+
+	compressors[c.ID()] = c
+}
+
+// WithCompression compresses binary snapshots, checkpoints and large
+// write-ahead log records with c. Reading accepts both compressed and
+// uncompressed data.
+func WithCompression[T comparable](c Compressor) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.compressor = c
+	}
+}
+
+// GzipCompressor compresses with compress/gzip at Level, or the default
+// level if Level is zero.
+type GzipCompressor struct {
+	Level int
+}
+
+func (GzipCompressor) ID() byte {
+	return CompressorGzip
+}
+
+func (g GzipCompressor) Compress(dst, src []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	buf := bytes.NewBuffer(dst)
+	w, err := gzip.NewWriterLevel(buf, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (GzipCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// lookupCompressor returns the compressor for id, preferring the buffer's own.
+func (b *PriorityRingBuffer[T]) lookupCompressor(id byte) (Compressor, error) {
+	if b.compressor != nil && b.compressor.ID() == id {
+		return b.compressor, nil
+	}
+
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+
+	c, ok := compressors[id]
+	if !ok {
+		return nil, ErrUnknownCompressor
+	}
+
+	return c, nil
+}
+
+// compressState wraps data, a binary snapshot, in the compressed format if
+// the buffer has a compressor.
+func compressState(data []byte, c Compressor) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+
+	return c.Compress(append([]byte(compressedMagic), c.ID()), data)
+}
+
+// decompressState unwraps a compressed binary snapshot. Other data is
+// returned unchanged.
+func (b *PriorityRingBuffer[T]) decompressState(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(compressedMagic)) {
+		return data, nil
+	}
+
+	if len(data) == len(compressedMagic) {
+		return nil, ErrInvalidFormat
+	}
+
+	c, err := b.lookupCompressor(data[len(compressedMagic)])
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Decompress(data[len(compressedMagic)+1:])
+}
+
+// compressRecord wraps a write-ahead log record in a walCompressed record if
+// the buffer has a compressor and the record is large enough.
+func (b *PriorityRingBuffer[T]) compressRecord(record []byte) ([]byte, error) {
+	if b.compressor == nil || len(record) < walCompressMin {
+		return record, nil
+	}
+
+	return b.compressor.Compress([]byte{walCompressed, b.compressor.ID()}, record)
+}
+
+// decompressRecord unwraps a walCompressed record.
+func (b *PriorityRingBuffer[T]) decompressRecord(record []byte) ([]byte, error) {
+	if len(record) < 2 {
+		return nil, ErrInvalidFormat
+	}
+
+	c, err := b.lookupCompressor(record[1])
+	if err != nil {
+		return nil, err
+	}
+
+	record, err = c.Decompress(record[2:])
+	if err == nil && len(record) == 0 {
+		err = ErrInvalidFormat
+	}
+
+	return record, err
+}
@@ -0,0 +1,115 @@
+package prb_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+// identityCompressor is a compressor that is never registered.
+type identityCompressor struct{}
+
+func (identityCompressor) ID() byte { return 200 }
+
+func (identityCompressor) Compress(dst, src []byte) ([]byte, error) {
+	return append(dst, src...), nil
+}
+
+func (identityCompressor) Decompress(src []byte) ([]byte, error) {
+	return slices.Clone(src), nil
+}
+
+type entry[T comparable] struct {
+	value    T
+	priority int
+}
+
+// contents returns the values and priorities of b in logical order.
+func contents[T comparable](b *prb.PriorityRingBuffer[T]) []entry[T] {
+	var out []entry[T]
+	for _, e := range b.Snapshot() {
+		out = append(out, entry[T]{e.Value, e.Priority})
+	}
+
+	return out
+}
+
+func TestCompression(t *testing.T) {
+	values := []string{strings.Repeat("a", 1000), "b", strings.Repeat("c", 300)}
+
+	tests := []struct {
+		name       string
+		compressor prb.Compressor
+		want       error
+	}{
+		{"none", nil, nil},
+		{"gzip", prb.GzipCompressor{}, nil},
+		{"gzip best", prb.GzipCompressor{Level: 9}, nil},
+		{"unregistered", identityCompressor{}, prb.ErrUnknownCompressor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []prb.Option[string]
+			if tt.compressor != nil {
+				opts = append(opts, prb.WithCompression[string](tt.compressor))
+			}
+
+			source := prb.MustNew[string](4, opts...)
+			path := filepath.Join(t.TempDir(), "wal")
+			logged, err := prb.OpenFromWAL[string](path, 4, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range values {
+				if err := source.Insert(v, i); err != nil {
+					t.Fatal(err)
+				}
+				if err := logged.Insert(v, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := logged.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := source.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Readers without the compressor find it in the registry.
+			b := prb.MustNew[string](4)
+			err = b.UnmarshalBinary(data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("UnmarshalBinary: %v, want %v", err, tt.want)
+			}
+			if err == nil && !slices.Equal(contents(b), contents(source)) {
+				t.Fatalf("UnmarshalBinary restored %v, want %v", contents(b), contents(source))
+			}
+
+			replayed, err := prb.ReplayWAL[string](path, 4)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ReplayWAL: %v, want %v", err, tt.want)
+			}
+			if err == nil && !slices.Equal(contents(replayed), contents(source)) {
+				t.Fatalf("ReplayWAL restored %v, want %v", contents(replayed), contents(source))
+			}
+
+			if tt.compressor == nil {
+				return
+			}
+			b = prb.MustNew[string](4, opts...)
+			if err := b.UnmarshalBinary(data); err != nil {
+				t.Fatalf("UnmarshalBinary with the compressor: %v", err)
+			}
+			if _, err := prb.ReplayWAL[string](path, 4, opts...); err != nil {
+				t.Fatalf("ReplayWAL with the compressor: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package prb
+
+// CompareAndDequeue atomically dequeues the head only if expect reports true
+// for it, so two consumers cannot both act on the same Peek. If expect
+// rejects the head, it is returned with false and stays queued. expect runs
+// under the write lock and must not call back into the buffer.
+func (b *PriorityRingBuffer[T]) CompareAndDequeue(expect func(Element[T]) bool) (Element[T], bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, false, ErrClosed
+	}
+
+	if b.size == 0 {
+		return Element[T]{}, false, ErrBufferEmpty
+	}
+
+	if head := b.front(); !expect(head) {
+		return head, false, nil
+	}
+
+	element, err := b.dequeue()
+	return element, err == nil, err
+}
+
+// DequeueIfPriorityAtLeast dequeues the head only if its priority is at
+// least minPriority. Otherwise the head is returned with false and stays queued,
+// so urgent work can be taken immediately and routine work left for a
+// slower consumer.
+func (b *PriorityRingBuffer[T]) DequeueIfPriorityAtLeast(minPriority int) (Element[T], bool, error) {
+	return b.CompareAndDequeue(func(e Element[T]) bool {
+		return e.Priority >= minPriority
+	})
+}
+
+// DequeueWhere removes and returns the first element, in dequeue order,
+// matching all filters, closing the gap it leaves. It fails with
+// ErrNotFound if nothing matches.
+func (b *PriorityRingBuffer[T]) DequeueWhere(filters ...SearchFilter[T]) (Element[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	if b.size == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	i, slot := -1, -1
+	b.each(func(j, s int) bool {
+		if matches(b.elements[s], filters) {
+			i, slot = j, s
+			return false
+		}
+		return true
+	})
+	if slot < 0 {
+		return Element[T]{}, ErrNotFound
+	}
+
+	if err := b.logRemoveAt(i); err != nil {
+		return Element[T]{}, err
+	}
+
+	return b.remove(slot), nil
+}
@@ -0,0 +1,23 @@
+package prb
+
+// Config describes a buffer to build: its capacity and the options to apply.
+// It lets a configuration be checked, for example at startup or when loaded
+// from a file, before any buffer is allocated.
+type Config[T comparable] struct {
+	Capacity int
+	Options  []Option[T]
+}
+
+// Validate reports every problem with the configuration, joined with
+// errors.Join when there are several, or nil if New would accept it. Each
+// problem matches its sentinel error, such as ErrInvalidCapacity or
+// ErrInvalidWindow, with errors.Is.
+func (c Config[T]) Validate() error {
+	_, err := configure(c.Capacity, c.Options)
+	return err
+}
+
+// New builds the buffer described by c.
+func (c Config[T]) New() (*PriorityRingBuffer[T], error) {
+	return New(c.Capacity, c.Options...)
+}
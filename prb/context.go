@@ -0,0 +1,107 @@
+package prb
+
+import "context"
+
+// scanChunk is how many elements a context-aware scan visits per lock
+// acquisition.
+const scanChunk = 1024
+
+// SearchContext is Search for large buffers: it holds the read lock for at
+// most a chunk of elements at a time, so writers are not stalled, and stops
+// with ctx.Err() once ctx is done. Because writers can run between chunks,
+// the result is only weakly consistent: an element moved by a concurrent
+// write may be reported twice or not at all.
+func (b *PriorityRingBuffer[T]) SearchContext(ctx context.Context, filters ...SearchFilter[T]) ([]int, error) {
+	var result []int
+	err := b.eachContext(ctx, func(i int, e Element[T]) bool {
+		if matches(e, filters) {
+			result = append(result, i)
+		}
+		return true
+	})
+
+	return result, err
+}
+
+// CountContext is Count scanning in chunks like SearchContext.
+func (b *PriorityRingBuffer[T]) CountContext(ctx context.Context, filters ...SearchFilter[T]) (int, error) {
+	count := 0
+	err := b.eachContext(ctx, func(i int, e Element[T]) bool {
+		if matches(e, filters) {
+			count++
+		}
+		return true
+	})
+
+	return count, err
+}
+
+// SearchFuncContext is SearchFunc scanning in chunks like SearchContext. fn
+// still runs under the read lock and must not call back into the buffer.
+func (b *PriorityRingBuffer[T]) SearchFuncContext(ctx context.Context, fn func(i int, e Element[T]) bool, filters ...SearchFilter[T]) error {
+	return b.eachContext(ctx, func(i int, e Element[T]) bool {
+		return !matches(e, filters) || fn(i, e)
+	})
+}
+
+// DrainContext dequeues every element, a chunk per lock acquisition, until
+// the buffer is empty or ctx is done. It returns the elements dequeued so
+// far either way.
+func (b *PriorityRingBuffer[T]) DrainContext(ctx context.Context) ([]Element[T], error) {
+	var drained []Element[T]
+	chunk := make([]Element[T], scanChunk)
+	for {
+		if err := ctx.Err(); err != nil {
+			return drained, err
+		}
+
+		n, err := b.DequeueInto(chunk)
+		drained = append(drained, chunk[:n]...)
+		if err != nil || n < len(chunk) {
+			return drained, err
+		}
+	}
+}
+
+// eachContext calls fn with the logical index and a copy of every element in
+// dequeue order until fn returns false, taking the read lock for one chunk
+// at a time and checking ctx in between.
+func (b *PriorityRingBuffer[T]) eachContext(ctx context.Context, fn func(i int, e Element[T]) bool) error {
+	var slots []int
+	var version uint64
+
+	for start := 0; ; start += scanChunk {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !b.eachChunk(start, &slots, &version, fn) {
+			return nil
+		}
+	}
+}
+
+// eachChunk visits the elements from logical index start under the read
+// lock. slots caches the heap layout's dequeue order for version. It reports
+// whether elements remain after the chunk and fn wants more.
+func (b *PriorityRingBuffer[T]) eachChunk(start int, slots *[]int, version *uint64, fn func(i int, e Element[T]) bool) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sorted && (*slots == nil || *version != b.version) {
+		*slots, *version = b.sortedSlots(), b.version
+	}
+
+	end := min(start+scanChunk, b.size)
+	for i := start; i < end; i++ {
+		slot := b.wrap(b.head + i)
+		if b.sorted {
+			slot = (*slots)[i]
+		}
+		if !fn(i, b.elements[slot]) {
+			return false
+		}
+	}
+
+	return end < b.size
+}
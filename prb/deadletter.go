@@ -0,0 +1,69 @@
+package prb
+
+import "errors"
+
+var (
+	ErrEvicted     = errors.New("element was evicted from a full buffer")
+	ErrMaxAttempts = errors.New("element was rejected too many times")
+)
+
+type deadLetter[T comparable] struct {
+	element Element[T]
+	reason  error
+}
+
+// WithDeadLetter inserts elements that leave the buffer without being
+// dequeued into dl, keeping their priority, key and tags. See
+// WithDeadLetterFunc for which elements are affected.
+func WithDeadLetter[T comparable](dl *PriorityRingBuffer[T]) Option[T] {
+	return WithDeadLetterFunc(func(e Element[T], reason error) {
+		if e.Key != "" {
+			dl.InsertKeyed(e.Key, e.Value, e.Priority)
+		} else {
+			dl.InsertWithMeta(e.Value, e.Priority, e.Tags)
+		}
+	})
+}
+
+// WithDeadLetterFunc calls fn with every element that is evicted to make
+// room (reason ErrEvicted), rejected by the overflow mode (the insert
+// error), or passed to Nack after WithMaxAttempts deliveries (reason
+// ErrMaxAttempts). fn runs after the buffer's lock is released, so it may
+// use the buffer, but not while replaying a write-ahead log.
+func WithDeadLetterFunc[T comparable](fn func(e Element[T], reason error)) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.deadLetter = fn
+	}
+}
+
+// WithMaxAttempts makes Nack and the ack timeout hand an element to the
+// dead letter instead of reinserting it once it has been delivered n times.
+func WithMaxAttempts[T comparable](n int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.maxAttempts = n
+	}
+}
+
+// bury queues e for the dead letter. The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) bury(e Element[T], reason error) {
+	if b.deadLetter != nil {
+		b.dead = append(b.dead, deadLetter[T]{element: e, reason: reason})
+	}
+}
+
+// flushDead passes buried elements to the dead letter. The caller must not
+// hold the lock.
+func (b *PriorityRingBuffer[T]) flushDead() {
+	if b.deadLetter == nil {
+		return
+	}
+
+	b.mu.Lock()
+	dead := b.dead
+	b.dead = nil
+	b.mu.Unlock()
+
+	for _, d := range dead {
+		b.deadLetter(d.element, d.reason)
+	}
+}
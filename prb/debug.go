@@ -0,0 +1,55 @@
+package prb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+func (b *PriorityRingBuffer[T]) String() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return fmt.Sprintf("PriorityRingBuffer{size: %d, capacity: %d, head: %d, tail: %d}",
+		b.size, b.capacity, b.head, b.tail)
+}
+
+// Dump writes the ring layout to w, one line per slot, marking head and
+// tail. Only live slots are printed unless verbose is set, in which case
+// stale slots outside head..tail are included as well.
+func (b *PriorityRingBuffer[T]) Dump(w io.Writer, verbose bool) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "size=%d capacity=%d head=%d tail=%d window=%d order=%d unordered=%t\n",
+		b.size, b.capacity, b.head, b.tail, b.bubbleWindow, b.orderCounter, b.unordered)
+
+	for slot := 0; slot < b.capacity; slot++ {
+		live := b.logical(slot) < b.size
+		if !live && !verbose {
+			continue
+		}
+
+		marker := "  "
+		switch {
+		case slot == b.head && slot == b.tail:
+			marker = "HT"
+		case slot == b.head:
+			marker = "H "
+		case slot == b.tail:
+			marker = " T"
+		}
+
+		state := "live "
+		if !live {
+			state = "stale"
+		}
+
+		e := b.elements[slot]
+		fmt.Fprintf(bw, "%s [%d] %s priority=%d order=%d value=%v\n",
+			marker, slot, state, e.Priority, e.InsertionOrder, e.Value)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,63 @@
+package prb
+
+import "time"
+
+// DecayFunc returns the effective priority of an element with the given
+// priority that has been queued for age.
+type DecayFunc func(priority int, age time.Duration) int
+
+// WithDecay makes Peek and Dequeue pick the element with the highest
+// decayed priority, oldest first among equals, so stale urgent work loses
+// out to fresh work. Decay is applied lazily: the stored priorities and ring
+// order are untouched, and Peek and Dequeue scan every element and report
+// the decayed priority. Other dequeue methods ignore decay. Elements restored
+// from a snapshot start aging when they are loaded.
+func WithDecay[T comparable](fn DecayFunc) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.decay = fn
+	}
+}
+
+// stamp records the insertion time that decay measures age from. It only
+// reads the clock when decay is on.
+func (b *PriorityRingBuffer[T]) stamp(e *Element[T]) {
+	if b.decay != nil && e.inserted.IsZero() {
+		e.inserted = b.clock.Now()
+	}
+}
+
+// decayedSlot returns the slot holding the element with the highest decayed
+// priority, and that priority. The buffer must not be empty.
+func (b *PriorityRingBuffer[T]) decayedSlot() (int, int) {
+	now := b.clock.Now()
+	best, bestPriority := -1, 0
+	for i := 0; i < b.size; i++ {
+		slot := b.wrap(b.head + i)
+		e := b.elements[slot]
+		priority := b.decay(e.Priority, now.Sub(e.inserted))
+		if best < 0 || priority > bestPriority ||
+			(priority == bestPriority && e.InsertionOrder < b.elements[best].InsertionOrder) {
+			best, bestPriority = slot, priority
+		}
+	}
+
+	return best, bestPriority
+}
+
+// dequeueDecayed removes the element with the highest decayed priority. It
+// is logged by position so replay does not depend on the clock.
+func (b *PriorityRingBuffer[T]) dequeueDecayed() (Element[T], error) {
+	slot, priority := b.decayedSlot()
+	if err := b.logRemoveAt(b.position(slot)); err != nil {
+		return Element[T]{}, err
+	}
+
+	element := b.take(slot)
+	element.Priority = priority
+	element.GuaranteedMax = true
+	b.counters.dequeues++
+	b.emit(EventDequeue, element, nil)
+
+	b.commit()
+	return element, nil
+}
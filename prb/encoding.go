@@ -0,0 +1,214 @@
+package prb
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidState = errors.New("encoded buffer state is inconsistent")
+
+// Snapshot is a complete, encoding independent copy of a buffer's
+// configuration and elements in dequeue order.
+type Snapshot[T comparable] struct {
+	Capacity     int          `json:"capacity"`
+	BubbleWindow int          `json:"bubbleWindow"`
+	OverflowMode OverflowMode `json:"overflowMode"`
+	OrderCounter int64        `json:"orderCounter"`
+	Quotas       []Quota      `json:"quotas,omitempty"`
+	Elements     []Element[T] `json:"elements"`
+}
+
+// state captures configuration and elements in dequeue order. The caller must
+// hold at least the read lock.
+func (b *PriorityRingBuffer[T]) state() Snapshot[T] {
+	elements := make([]Element[T], b.size)
+	b.each(func(i, slot int) bool {
+		elements[i] = b.elements[slot]
+		return true
+	})
+
+	var quotas []Quota
+	for _, q := range b.quotas {
+		quotas = append(quotas, q.Quota)
+	}
+
+	return Snapshot[T]{
+		Capacity:     b.capacity,
+		BubbleWindow: b.bubbleWindow,
+		OverflowMode: b.overflow,
+		OrderCounter: b.orderCounter,
+		Quotas:       quotas,
+		Elements:     elements,
+	}
+}
+
+// load replaces the buffer contents and configuration with s, laying the
+// elements out from slot zero. Nothing changes unless s is valid. The caller
+// must hold the write lock.
+func (b *PriorityRingBuffer[T]) load(s Snapshot[T]) error {
+	if b.closed {
+		return ErrClosed
+	}
+
+	restored := PriorityRingBuffer[T]{
+		capacity:     s.Capacity,
+		bubbleWindow: s.BubbleWindow,
+		overflow:     s.OverflowMode,
+		orderCounter: s.OrderCounter,
+	}
+	for _, q := range s.Quotas {
+		restored.quotas = append(restored.quotas, quota{Quota: q})
+	}
+
+	if err := restored.validateConfig(); err != nil {
+		return err
+	}
+
+	if len(s.Elements) > s.Capacity {
+		return ErrInvalidState
+	}
+
+	keys := make(map[string]struct{})
+	for i, e := range s.Elements {
+		if e.InsertionOrder >= s.OrderCounter {
+			return ErrInvalidState
+		}
+		if e.Key != "" {
+			if _, ok := keys[e.Key]; ok {
+				return ErrInvalidState
+			}
+			keys[e.Key] = struct{}{}
+		}
+		if i > 0 && restored.shouldSwap(e, s.Elements[i-1]) {
+			restored.unordered = true
+		}
+	}
+
+	if b.store != nil && s.Capacity != b.capacity {
+		return ErrInvalidState
+	}
+
+	if b.logging() {
+		if err := b.logState(s); err != nil {
+			return err
+		}
+	}
+
+	// Buffers decoded into without New have no clock yet.
+	if b.clock == nil {
+		b.clock = realClock{}
+	}
+
+	restored.elements = make([]Element[T], s.Capacity)
+	for _, e := range s.Elements {
+		e.GuaranteedMax = false
+		b.stamp(&e)
+		restored.elements[restored.size] = e
+		restored.size++
+		restored.added(e)
+	}
+	restored.tail = restored.size % restored.capacity
+
+	if b.spill != nil {
+		if err := b.spill.reset(); err != nil {
+			return err
+		}
+	}
+
+	b.elements = restored.elements
+	b.capacity = restored.capacity
+	b.pow2 = b.capacity&(b.capacity-1) == 0
+	b.head = restored.head
+	b.tail = restored.tail
+	b.size = restored.size
+	b.bubbleWindow = restored.bubbleWindow
+	b.orderCounter = restored.orderCounter
+	b.overflow = restored.overflow
+	b.unordered = restored.unordered
+	b.quotas = restored.quotas
+	b.counters.highWatermark = max(b.counters.highWatermark, b.size)
+
+	b.sorted = b.wantsHeap()
+	b.indexKeys()
+	b.cachedMax.Store(-1)
+	b.heapReset()
+	if b.sorted {
+		b.unordered = false
+		b.heapify()
+	} else {
+		for i := 0; i < b.size; i++ {
+			b.heapAdd(i)
+		}
+	}
+
+	if b.store != nil {
+		for i := 0; i < b.size; i++ {
+			b.store.storeSlot(i, b.elements[i])
+		}
+	}
+	b.commit()
+
+	return nil
+}
+
+// Capture returns a consistent copy of the buffer state.
+func (b *PriorityRingBuffer[T]) Capture() (Snapshot[T], error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.state(), nil
+}
+
+// Restore atomically replaces the buffer state with s. The buffer is left
+// untouched if s is invalid.
+func (b *PriorityRingBuffer[T]) Restore(s Snapshot[T]) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.load(s)
+}
+
+func (b *PriorityRingBuffer[T]) MarshalJSON() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return json.Marshal(b.state())
+}
+
+func (b *PriorityRingBuffer[T]) UnmarshalJSON(data []byte) error {
+	var s Snapshot[T]
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.load(s)
+}
+
+func (b *PriorityRingBuffer[T]) GobEncode() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(b.state()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (b *PriorityRingBuffer[T]) GobDecode(data []byte) error {
+	var s Snapshot[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.load(s)
+}
@@ -0,0 +1,72 @@
+package prb_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"GoPRB/prb"
+)
+
+func TestDecodeIntoZeroValue(t *testing.T) {
+	source := prb.MustNew[string](4)
+	for i, v := range []string{"a", "b", "c"} {
+		if err := source.Insert(v, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		decode func(b *prb.PriorityRingBuffer[string]) error
+	}{
+		{"json", func(b *prb.PriorityRingBuffer[string]) error {
+			data, err := json.Marshal(source)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(data, b)
+		}},
+		{"gob", func(b *prb.PriorityRingBuffer[string]) error {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(source); err != nil {
+				return err
+			}
+			return gob.NewDecoder(&buf).Decode(b)
+		}},
+		{"binary", func(b *prb.PriorityRingBuffer[string]) error {
+			data, err := source.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			return b.UnmarshalBinary(data)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b prb.PriorityRingBuffer[string]
+			if err := tt.decode(&b); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got := b.Len(); got != 3 {
+				t.Fatalf("Len() = %d, want 3", got)
+			}
+
+			if err := b.Insert("d", 3); err != nil {
+				t.Fatalf("Insert: %v", err)
+			}
+			if err := b.InsertTimeout("e", 4, time.Millisecond); err != nil {
+				t.Fatalf("InsertTimeout: %v", err)
+			}
+			if _, err := b.DequeueTimeout(time.Millisecond); err != nil {
+				t.Fatalf("DequeueTimeout: %v", err)
+			}
+			if err := b.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package prb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEncrypted      = errors.New("data is encrypted and the buffer has no key provider")
+	ErrUnknownKey     = errors.New("data was encrypted with a key the provider does not have")
+	ErrUnencrypted    = errors.New("record is not encrypted but the buffer has a key provider")
+	ErrRecordSequence = errors.New("encrypted record is out of sequence")
+)
+
+// Encrypted snapshots are encryptedMagic, the key ID, the nonce and the
+// sealed binary snapshot, compressed first if the buffer compresses. The
+// magic and key ID are authenticated as additional data.
+const encryptedMagic = "GPRE"
+
+// keyIDLen is the length of the big-endian key ID in encrypted data.
+const keyIDLen = 4
+
+// Encrypted write-ahead log records are walEncrypted, the log ID and record
+// number of their sequence, both big-endian, the key ID, the nonce and the
+// sealed record. Everything before the nonce is authenticated.
+const recordHeaderLen = 1 + 8 + 8
+
+// sequence is the position of encrypted records in a write-ahead log: a
+// random ID for the log, which changes when the log is compacted, and the
+// number of the next record. Replay checks that records are numbered from
+// zero without gaps, so records cannot be reordered, dropped, repeated or
+// moved between logs unnoticed, except for cutting off the end of a log.
+type sequence struct {
+	log  uint64
+	next uint64
+}
+
+func newSequence() (sequence, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return sequence{}, err
+	}
+
+	return sequence{log: binary.BigEndian.Uint64(id[:])}, nil
+}
+
+// KeyProvider supplies the AEAD keys for encryption at rest. Key IDs are
+// recorded with the encrypted data, so keys can be rotated by changing the
+// current key while older ones stay available for reading.
+type KeyProvider interface {
+	// CurrentKey returns the key to encrypt new data with and its ID.
+	CurrentKey() (uint32, cipher.AEAD, error)
+	// Key returns the key with the given ID, or ErrUnknownKey.
+	Key(id uint32) (cipher.AEAD, error)
+}
+
+// WithEncryption encrypts binary snapshots, checkpoints and write-ahead log
+// records with keys. Snapshots may still be read unencrypted, but replaying
+// a log fails on records that are not encrypted. Followers of an encrypted
+// buffer need the same keys.
+func WithEncryption[T comparable](keys KeyProvider) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.keyProvider = keys
+	}
+}
+
+// WithEncryptionKey is WithEncryption with a single AES-GCM key with ID
+// zero. The key must be 16, 24 or 32 bytes long.
+func WithEncryptionKey[T comparable](key []byte) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.keyProvider, b.keyErr = NewAESKeys(0, map[uint32][]byte{0: key})
+	}
+}
+
+type aesKeys struct {
+	current uint32
+	keys    map[uint32]cipher.AEAD
+}
+
+// NewAESKeys returns a KeyProvider with an AES-GCM key for every entry of
+// keys that encrypts with the key with ID current.
+func NewAESKeys(current uint32, keys map[uint32][]byte) (KeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, ErrUnknownKey
+	}
+
+	k := &aesKeys{current: current, keys: make(map[uint32]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+func (k *aesKeys) CurrentKey() (uint32, cipher.AEAD, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *aesKeys) Key(id uint32) (cipher.AEAD, error) {
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return aead, nil
+}
+
+// seal appends the key ID, a random nonce and data sealed with the current
+// key to header, which is authenticated along with it.
+func seal(header, data []byte, keys KeyProvider) ([]byte, error) {
+	id, aead, err := keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	out := binary.BigEndian.AppendUint32(header, id)
+	ad := len(out)
+	out = append(out, make([]byte, aead.NonceSize())...)
+	if _, err := rand.Read(out[ad:]); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, out[ad:], data, out[:ad]), nil
+}
+
+// open reverses seal for data that starts with a header of headerLen bytes.
+func open(data []byte, headerLen int, keys KeyProvider) ([]byte, error) {
+	if keys == nil {
+		return nil, ErrEncrypted
+	}
+
+	ad := headerLen + keyIDLen
+	if len(data) < ad {
+		return nil, ErrInvalidFormat
+	}
+
+	aead, err := keys.Key(binary.BigEndian.Uint32(data[headerLen:]))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < ad+aead.NonceSize() {
+		return nil, ErrInvalidFormat
+	}
+
+	nonce := data[ad : ad+aead.NonceSize()]
+	return aead.Open(nil, nonce, data[ad+aead.NonceSize():], data[:ad])
+}
+
+// encryptState wraps data, a possibly compressed binary snapshot, in the
+// encrypted format if the buffer has a key provider.
+func encryptState(data []byte, keys KeyProvider) ([]byte, error) {
+	if keys == nil {
+		return data, nil
+	}
+
+	return seal([]byte(encryptedMagic), data, keys)
+}
+
+// decryptState unwraps an encrypted binary snapshot. Other data is returned
+// unchanged.
+func (b *PriorityRingBuffer[T]) decryptState(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		return data, nil
+	}
+
+	return open(data, len(encryptedMagic), b.keyProvider)
+}
+
+// encryptRecord wraps a write-ahead log record in a walEncrypted record
+// numbered by seq, if the buffer has a key provider.
+func (b *PriorityRingBuffer[T]) encryptRecord(record []byte, seq *sequence) ([]byte, error) {
+	if b.keyProvider == nil {
+		return record, nil
+	}
+
+	header := binary.BigEndian.AppendUint64([]byte{walEncrypted}, seq.log)
+	header = binary.BigEndian.AppendUint64(header, seq.next)
+	seq.next++
+
+	return seal(header, record, b.keyProvider)
+}
+
+// decryptRecord unwraps a walEncrypted record and returns its position.
+func (b *PriorityRingBuffer[T]) decryptRecord(record []byte) ([]byte, sequence, error) {
+	var seq sequence
+	if len(record) >= recordHeaderLen {
+		seq.log = binary.BigEndian.Uint64(record[1:])
+		seq.next = binary.BigEndian.Uint64(record[9:])
+	}
+
+	record, err := open(record, recordHeaderLen, b.keyProvider)
+	if err == nil && len(record) == 0 {
+		err = ErrInvalidFormat
+	}
+
+	return record, seq, err
+}
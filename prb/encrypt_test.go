@@ -0,0 +1,179 @@
+package prb_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+var (
+	key0 = bytes.Repeat([]byte{0}, 32)
+	key1 = bytes.Repeat([]byte{1}, 32)
+)
+
+func aesKeys(t *testing.T, current uint32, keys map[uint32][]byte) prb.KeyProvider {
+	t.Helper()
+
+	k, err := prb.NewAESKeys(current, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func TestSnapshotEncryption(t *testing.T) {
+	source := prb.MustNew[string](4, prb.WithEncryptionKey[string](key0))
+	if err := source.Insert("secret", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := source.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("snapshot contains the plaintext value")
+	}
+
+	tests := []struct {
+		name    string
+		keys    prb.KeyProvider
+		wantErr bool
+		want    error
+	}{
+		{"same key", aesKeys(t, 0, map[uint32][]byte{0: key0}), false, nil},
+		{"rotated", aesKeys(t, 1, map[uint32][]byte{0: key0, 1: key1}), false, nil},
+		{"no keys", nil, true, prb.ErrEncrypted},
+		{"unknown key", aesKeys(t, 1, map[uint32][]byte{1: key1}), true, prb.ErrUnknownKey},
+		{"wrong key", aesKeys(t, 0, map[uint32][]byte{0: key1}), true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []prb.Option[string]
+			if tt.keys != nil {
+				opts = append(opts, prb.WithEncryption[string](tt.keys))
+			}
+
+			b := prb.MustNew[string](4, opts...)
+			err := b.UnmarshalBinary(data)
+			if (err != nil) != tt.wantErr || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Fatalf("UnmarshalBinary: %v, want %v", err, tt.want)
+			}
+			if err == nil && !slices.Equal(contents(b), contents(source)) {
+				t.Fatalf("UnmarshalBinary restored %v, want %v", contents(b), contents(source))
+			}
+		})
+	}
+
+	// Plain snapshots stay readable.
+	plain, err := prb.MustNew[string](4).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prb.MustNew[string](4, prb.WithEncryptionKey[string](key0)).UnmarshalBinary(plain); err != nil {
+		t.Fatalf("UnmarshalBinary of a plain snapshot: %v", err)
+	}
+}
+
+// frames splits a checked log after its header into its framed records.
+func frames(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+
+	var out [][]byte
+	for data = data[len("GPRW")+1:]; len(data) > 0; {
+		length, n := binary.Uvarint(data)
+		end := n + int(length) + 4
+		if n <= 0 || end > len(data) {
+			t.Fatal("bad frame")
+		}
+		out = append(out, data[:end])
+		data = data[end:]
+	}
+
+	return out
+}
+
+func TestWALEncryption(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, opts ...prb.Option[string]) (string, []byte) {
+		path := filepath.Join(dir, name)
+		b, err := prb.OpenFromWAL[string](path, 4, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range []string{"aaaa", "bbbb", "cccc"} {
+			if err := b.Insert(v, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := b.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path, data
+	}
+
+	encrypted, data := write("encrypted", prb.WithEncryptionKey[string](key0))
+	if bytes.Contains(data, []byte("bbbb")) {
+		t.Fatal("log contains a plaintext value")
+	}
+	plain, _ := write("plain")
+
+	records := frames(t, data)
+	header := data[:len("GPRW")+1]
+	swapped := filepath.Join(dir, "swapped")
+	if err := os.WriteFile(swapped, bytes.Join([][]byte{header, records[0], records[2], records[1], records[3]}, nil), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spliced := filepath.Join(dir, "spliced")
+	if err := os.WriteFile(spliced, bytes.Join([][]byte{data, records[1]}, nil), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		keys       bool
+		want       error
+		corruption bool
+	}{
+		{"encrypted", encrypted, true, nil, false},
+		{"no keys", encrypted, false, prb.ErrEncrypted, false},
+		{"plaintext", plain, true, prb.ErrUnencrypted, true},
+		{"reordered", swapped, true, prb.ErrRecordSequence, true},
+		{"repeated", spliced, true, prb.ErrRecordSequence, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []prb.Option[string]
+			if tt.keys {
+				opts = append(opts, prb.WithEncryptionKey[string](key0))
+			}
+
+			b, err := prb.ReplayWAL[string](tt.path, 4, opts...)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ReplayWAL: %v, want %v", err, tt.want)
+			}
+			var corruption *prb.CorruptionError
+			if errors.As(err, &corruption) != tt.corruption {
+				t.Fatalf("ReplayWAL: %T, want *CorruptionError %v", err, tt.corruption)
+			}
+			if err == nil && b.Len() != 3 {
+				t.Fatalf("Len() = %d, want 3", b.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package prb
+
+type EventKind int
+
+const (
+	EventInsert EventKind = iota
+	EventDequeue
+	EventEvict
+	EventReject
+	EventClear
+	EventRemove
+	EventHighWatermark
+	EventLowWatermark
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventInsert:
+		return "insert"
+	case EventDequeue:
+		return "dequeue"
+	case EventEvict:
+		return "evict"
+	case EventReject:
+		return "reject"
+	case EventClear:
+		return "clear"
+	case EventRemove:
+		return "remove"
+	case EventHighWatermark:
+		return "high watermark"
+	case EventLowWatermark:
+		return "low watermark"
+	}
+
+	return "unknown"
+}
+
+// Event describes one buffer operation. Element is a copy of the element
+// inserted, dequeued, evicted, rejected or removed out of order, and zero
+// for watermark events; Err holds the rejection reason.
+type Event[T comparable] struct {
+	Kind    EventKind
+	Element Element[T]
+	Err     error
+}
+
+const defaultEventBuffer = 256
+
+// WithEventBuffer sets the capacity of the channel returned by Events.
+func WithEventBuffer[T comparable](size int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.eventBuffer = size
+	}
+}
+
+// Events returns the buffer's event stream, creating it on first use. Events
+// are dropped rather than blocking when the consumer falls behind; the
+// number dropped is reported in Stats. The channel is closed by Close.
+func (b *PriorityRingBuffer[T]) Events() <-chan Event[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.events == nil {
+		size := b.eventBuffer
+		if size <= 0 {
+			size = defaultEventBuffer
+		}
+		b.events = make(chan Event[T], size)
+	}
+
+	return b.events
+}
+
+func (b *PriorityRingBuffer[T]) emit(kind EventKind, element Element[T], err error) {
+	if b.events == nil {
+		return
+	}
+
+	select {
+	case b.events <- Event[T]{Kind: kind, Element: element, Err: err}:
+	default:
+		b.counters.droppedEvents++
+	}
+}
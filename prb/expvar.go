@@ -0,0 +1,24 @@
+package prb
+
+import "expvar"
+
+// PublishExpvar exports live size, capacity and operation counters under
+// name. Like expvar.Publish it panics if name is already registered.
+func (b *PriorityRingBuffer[T]) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+
+		return map[string]any{
+			"size":            b.size,
+			"capacity":        b.capacity,
+			"inserts":         b.counters.inserts,
+			"dequeues":        b.counters.dequeues,
+			"overwrites":      b.counters.overwrites,
+			"rejections":      b.counters.rejections,
+			"quotaRejections": b.counters.quotaRejections,
+			"rateRejections":  b.counters.rateRejections,
+			"removals":        b.counters.removals,
+		}
+	}))
+}
@@ -0,0 +1,91 @@
+package prb
+
+import "errors"
+
+var ErrShrinkTooSmall = errors.New("capacity is smaller than the number of queued elements")
+
+// WithAutoGrow makes Insert double the capacity of a full buffer instead of
+// applying the overflow mode, up to maxCapacity, or without limit if
+// maxCapacity is zero. The overflow mode only applies once the limit is
+// reached. A fully ordered buffer keeps its bubble window at capacity-1 as it
+// grows. Memory-mapped buffers never grow.
+func WithAutoGrow[T comparable](maxCapacity int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.autoGrow = true
+		b.maxCapacity = maxCapacity
+	}
+}
+
+// ShrinkTo moves the contents into a smaller backing array with room for
+// capacity elements, releasing the memory of the old one, for example after
+// a spike has grown the buffer. It fails with ErrShrinkTooSmall if more than
+// capacity elements are queued and with ErrInvalidCapacity if capacity is
+// not positive or larger than the current capacity. A fully ordered buffer
+// stays fully ordered; otherwise the bubble window, and the range of an
+// adaptive window, are cut to capacity-1 if they no longer fit.
+func (b *PriorityRingBuffer[T]) ShrinkTo(capacity int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if capacity <= 0 || capacity > b.capacity {
+		return ErrInvalidCapacity
+	}
+
+	if capacity < b.size {
+		return ErrShrinkTooSmall
+	}
+
+	if b.store != nil {
+		return ErrMmapResize
+	}
+
+	if err := b.logShrink(capacity); err != nil {
+		return err
+	}
+
+	b.resize(capacity)
+	b.commit()
+	return nil
+}
+
+// canGrow reports whether a full buffer grows instead of overflowing.
+func (b *PriorityRingBuffer[T]) canGrow() bool {
+	return b.autoGrow && b.store == nil && (b.maxCapacity == 0 || b.capacity < b.maxCapacity)
+}
+
+// grow doubles the capacity, up to the auto grow limit, laying the contents
+// out from slot zero. The caller must hold the write lock and commit
+// afterwards.
+func (b *PriorityRingBuffer[T]) grow() {
+	capacity := b.capacity * 2
+	if b.maxCapacity > 0 {
+		capacity = min(capacity, b.maxCapacity)
+	}
+
+	b.resize(capacity)
+}
+
+// resize moves the contents into a new backing array with room for capacity
+// elements. A fully ordered buffer stays fully ordered; otherwise the bubble
+// window is kept if it still fits. The caller must hold the write lock and
+// commit afterwards.
+func (b *PriorityRingBuffer[T]) resize(capacity int) {
+	elements := b.state().Elements
+	if b.sorted {
+		b.bubbleWindow = capacity - 1
+	}
+	b.bubbleWindow = min(b.bubbleWindow, capacity-1)
+	if b.adaptive != nil {
+		b.adaptive.fit(capacity)
+	}
+
+	b.capacity = capacity
+	b.elements = make([]Element[T], capacity)
+	b.pow2 = capacity&(capacity-1) == 0
+	b.sorted = b.wantsHeap()
+	b.relayout(elements)
+}
@@ -0,0 +1,206 @@
+package prb
+
+// WithMaxIndex keeps a binary max-heap of occupied slots alongside the ring,
+// making PeekMaxPriority O(1) and DequeueMax O(log n) plus the ring shift,
+// at the cost of two ints per slot and heap maintenance on every insert.
+func WithMaxIndex[T comparable]() Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.maxIndex = true
+	}
+}
+
+// DequeueMax removes and returns the highest priority element, oldest first
+// among equal priorities, regardless of its ring position.
+func (b *PriorityRingBuffer[T]) DequeueMax() (Element[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	if b.size == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	if err := b.logOp(walDequeueMax); err != nil {
+		return Element[T]{}, err
+	}
+
+	element := b.take(b.maxSlot())
+	element.GuaranteedMax = true
+	b.counters.dequeues++
+	b.emit(EventDequeue, element, nil)
+
+	b.commit()
+	return element, nil
+}
+
+// maxSlot returns the slot holding the highest ranked element. The buffer
+// must not be empty.
+func (b *PriorityRingBuffer[T]) maxSlot() int {
+	if b.sorted {
+		return b.head
+	}
+
+	if b.heap != nil {
+		return b.heap[0]
+	}
+
+	if b.maxCache {
+		if cached := b.cachedMax.Load(); cached >= 0 {
+			return int(cached)
+		}
+	}
+
+	maxIndex := b.head
+	for i := 1; i < b.size; i++ {
+		index := b.wrap(b.head + i)
+		if outranks(b.elements[index], b.elements[maxIndex]) {
+			maxIndex = index
+		}
+	}
+
+	if b.maxCache {
+		b.cachedMax.Store(int64(maxIndex))
+	}
+
+	return maxIndex
+}
+
+// WithMaxCache remembers the slot of the highest ranked element so repeated
+// PeekMaxPriority calls are O(1). Inserts keep the cache current; removing
+// the cached element invalidates it until the next scan.
+func WithMaxCache[T comparable]() Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.maxCache = true
+	}
+}
+
+// cacheInserted updates the cached maximum after an element landed in slot.
+func (b *PriorityRingBuffer[T]) cacheInserted(slot int) {
+	if !b.maxCache {
+		return
+	}
+
+	cached := b.cachedMax.Load()
+	if b.size == 1 || (cached >= 0 && outranks(b.elements[slot], b.elements[cached])) {
+		b.cachedMax.Store(int64(slot))
+	}
+}
+
+// logical converts a slot index into a position counted from head.
+func (b *PriorityRingBuffer[T]) logical(index int) int {
+	return b.wrap(index - b.head)
+}
+
+func (b *PriorityRingBuffer[T]) heapReset() {
+	if !b.maxIndex || b.sorted {
+		b.heap, b.heapPos = nil, nil
+		return
+	}
+
+	b.heap = make([]int, 0, b.capacity)
+	b.heapPos = make([]int, b.capacity)
+	for i := range b.heapPos {
+		b.heapPos[i] = -1
+	}
+}
+
+func (b *PriorityRingBuffer[T]) heapAdd(slot int) {
+	if b.heapPos == nil {
+		return
+	}
+
+	b.heap = append(b.heap, slot)
+	b.heapPos[slot] = len(b.heap) - 1
+	b.heapUp(len(b.heap) - 1)
+}
+
+func (b *PriorityRingBuffer[T]) heapRemove(slot int) {
+	if b.heapPos == nil || b.heapPos[slot] < 0 {
+		return
+	}
+
+	pos := b.heapPos[slot]
+	last := len(b.heap) - 1
+	b.heapSwap(pos, last)
+	b.heap = b.heap[:last]
+	b.heapPos[slot] = -1
+
+	if pos < last {
+		b.heapFix(pos)
+	}
+}
+
+// heapUpdate restores heap order after the element in slot changed.
+func (b *PriorityRingBuffer[T]) heapUpdate(slot int) {
+	if b.heapPos == nil || b.heapPos[slot] < 0 {
+		return
+	}
+
+	b.heapFix(b.heapPos[slot])
+}
+
+// heapMove follows an element moving between slots; its rank is unchanged.
+func (b *PriorityRingBuffer[T]) heapMove(from, to int) {
+	if b.heapPos == nil {
+		return
+	}
+
+	pos := b.heapPos[from]
+	b.heapPos[from] = -1
+	b.heapPos[to] = pos
+	if pos >= 0 {
+		b.heap[pos] = to
+	}
+}
+
+func (b *PriorityRingBuffer[T]) heapFix(pos int) {
+	if !b.heapUp(pos) {
+		b.heapDown(pos)
+	}
+}
+
+func (b *PriorityRingBuffer[T]) heapLess(i, j int) bool {
+	return outranks(b.elements[b.heap[i]], b.elements[b.heap[j]])
+}
+
+func (b *PriorityRingBuffer[T]) heapSwap(i, j int) {
+	b.heap[i], b.heap[j] = b.heap[j], b.heap[i]
+	b.heapPos[b.heap[i]] = i
+	b.heapPos[b.heap[j]] = j
+}
+
+func (b *PriorityRingBuffer[T]) heapUp(pos int) bool {
+	moved := false
+	for pos > 0 {
+		parent := (pos - 1) / 2
+		if !b.heapLess(pos, parent) {
+			break
+		}
+		b.heapSwap(pos, parent)
+		pos = parent
+		moved = true
+	}
+
+	return moved
+}
+
+func (b *PriorityRingBuffer[T]) heapDown(pos int) {
+	for {
+		best := pos
+		for _, child := range [2]int{2*pos + 1, 2*pos + 2} {
+			if child < len(b.heap) && b.heapLess(child, best) {
+				best = child
+			}
+		}
+
+		if best == pos {
+			return
+		}
+
+		b.heapSwap(pos, best)
+		pos = best
+	}
+}
@@ -0,0 +1,52 @@
+package prb
+
+import "sync"
+
+// IndirectPRB stores *T in the ring so inserting, bubbling and dequeuing
+// move pointers rather than large values. Envelopes can be recycled through
+// Acquire and Release; callers that never Release simply allocate.
+// Value filters such as SearchByValue compare pointers, not pointees.
+type IndirectPRB[T any] struct {
+	*PriorityRingBuffer[*T]
+	pool sync.Pool
+}
+
+func NewIndirect[T any](capacity int, opts ...Option[*T]) (*IndirectPRB[T], error) {
+	ring, err := New(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &IndirectPRB[T]{PriorityRingBuffer: ring}
+	b.pool.New = func() any {
+		return new(T)
+	}
+
+	return b, nil
+}
+
+// Acquire returns a zeroed envelope, reusing a released one when available.
+func (b *IndirectPRB[T]) Acquire() *T {
+	return b.pool.Get().(*T)
+}
+
+// Release zeroes v and makes it available to Acquire. v must not be used
+// afterwards, and must no longer be queued.
+func (b *IndirectPRB[T]) Release(v *T) {
+	var zero T
+	*v = zero
+	b.pool.Put(v)
+}
+
+// InsertCopy copies v into a pooled envelope and inserts it.
+func (b *IndirectPRB[T]) InsertCopy(v T, priority int) error {
+	p := b.Acquire()
+	*p = v
+
+	if err := b.Insert(p, priority); err != nil {
+		b.Release(p)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package prb
+
+// InsertKeyed adds value under key, or, if an element with key is already
+// queued, replaces it: the element takes the new value and priority, keeps
+// its insertion order and is re-placed as a fresh insert would be. The
+// buffer thus holds at most the latest state per key. An empty key behaves
+// like Insert.
+func (b *PriorityRingBuffer[T]) InsertKeyed(key string, value T, priority int) error {
+	_, _, err := b.insert(Element[T]{Value: value, Priority: priority, Key: key}, nil, nil)
+	return err
+}
+
+// keySlot returns the slot of the element with key, or -1 if there is none
+// or key is empty. Keyed elements are tracked in a map from key to slot, so
+// this is O(1).
+func (b *PriorityRingBuffer[T]) keySlot(key string) int {
+	if key == "" {
+		return -1
+	}
+
+	if slot, ok := b.keys[key]; ok {
+		return slot
+	}
+
+	return -1
+}
+
+// ContainsKey reports whether an element with key is queued.
+func (b *PriorityRingBuffer[T]) ContainsKey(key string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.keySlot(key) >= 0
+}
+
+// reindex keeps the key index current when the element in slot changes from
+// old to e. Slots vacated by a move keep a stale copy of their element, so
+// old is only unindexed if the index still points at slot.
+func (b *PriorityRingBuffer[T]) reindex(slot int, old, e Element[T]) {
+	if old.Key != "" {
+		if s, ok := b.keys[old.Key]; ok && s == slot {
+			delete(b.keys, old.Key)
+		}
+	}
+
+	if e.Key != "" {
+		if b.keys == nil {
+			b.keys = make(map[string]int)
+		}
+		b.keys[e.Key] = slot
+	}
+}
+
+// indexKeys rebuilds the key index after the slots were rewritten directly.
+func (b *PriorityRingBuffer[T]) indexKeys() {
+	b.keys = nil
+	for i := 0; i < b.size; i++ {
+		slot := b.wrap(b.head + i)
+		b.reindex(slot, Element[T]{}, b.elements[slot])
+	}
+}
+
+// DequeueByKey removes and returns the element with key, wherever it is
+// queued, or fails with ErrNotFound.
+func (b *PriorityRingBuffer[T]) DequeueByKey(key string) (Element[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	slot := b.keySlot(key)
+	if slot < 0 {
+		return Element[T]{}, ErrNotFound
+	}
+
+	if err := b.logRemoveAt(b.position(slot)); err != nil {
+		return Element[T]{}, err
+	}
+
+	return b.remove(slot), nil
+}
+
+// RemoveByValue removes and returns the first element, in dequeue order,
+// whose value equals value, or fails with ErrNotFound.
+func (b *PriorityRingBuffer[T]) RemoveByValue(value T) (Element[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	i, slot := -1, -1
+	b.each(func(j, s int) bool {
+		if b.elements[s].Value == value {
+			i, slot = j, s
+			return false
+		}
+		return true
+	})
+	if slot < 0 {
+		return Element[T]{}, ErrNotFound
+	}
+
+	if err := b.logRemoveAt(i); err != nil {
+		return Element[T]{}, err
+	}
+
+	return b.remove(slot), nil
+}
+
+// PeekByKey returns the element with key without removing it, or fails with
+// ErrNotFound.
+func (b *PriorityRingBuffer[T]) PeekByKey(key string) (Element[T], error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	slot := b.keySlot(key)
+	if slot < 0 {
+		return Element[T]{}, ErrNotFound
+	}
+
+	return b.elements[slot], nil
+}
+
+// IndexOfKey returns the logical position of the element with key, the
+// number of elements that would be dequeued before it, or fails with
+// ErrNotFound.
+func (b *PriorityRingBuffer[T]) IndexOfKey(key string) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	slot := b.keySlot(key)
+	if slot < 0 {
+		return 0, ErrNotFound
+	}
+
+	return b.position(slot), nil
+}
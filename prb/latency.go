@@ -0,0 +1,135 @@
+package prb
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Latency histograms split every power of two into 1<<latencySubBits linear
+// sub-buckets, like HDR histograms, so every recorded duration is known to
+// within 25% with a fixed number of buckets covering nanoseconds to hours.
+const (
+	latencySubBits = 2
+	latencySub     = 1 << latencySubBits
+	latencyBuckets = (64 - latencySubBits + 1) * latencySub
+)
+
+// LatencyStats holds the latency histograms recorded by a buffer created
+// with WithLatencyHistograms. Insert covers every insert and Dequeue every
+// removal of the head, excluding time spent waiting for room or elements.
+// Search covers Search.
+type LatencyStats struct {
+	Insert  LatencyHistogram
+	Dequeue LatencyHistogram
+	Search  LatencyHistogram
+}
+
+// LatencyHistogram is a snapshot of a latency histogram. Buckets lists the
+// non-empty buckets in ascending order.
+type LatencyHistogram struct {
+	Count   uint64
+	Sum     time.Duration
+	Buckets []LatencyBucket `json:",omitempty"`
+}
+
+// LatencyBucket counts the recorded durations below UpperBound and at or
+// above the previous bucket's bound.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// Quantile returns the upper bound of the bucket holding the q-quantile, 0
+// <= q <= 1, or zero if nothing was recorded.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	rank := uint64(q * float64(h.Count))
+	seen := uint64(0)
+	for _, bucket := range h.Buckets {
+		seen += bucket.Count
+		if seen > rank || seen == h.Count {
+			return bucket.UpperBound
+		}
+	}
+
+	return 0
+}
+
+// WithLatencyHistograms records the duration of every insert, dequeue and
+// search into histograms reported by GetStats. Recording takes a clock
+// reading and a few atomic additions per operation.
+func WithLatencyHistograms[T comparable]() Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.latency = &latencies{}
+	}
+}
+
+type latencies struct {
+	insert, dequeue, search latencyHistogram
+}
+
+func (l *latencies) stats() *LatencyStats {
+	return &LatencyStats{
+		Insert:  l.insert.snapshot(),
+		Dequeue: l.dequeue.snapshot(),
+		Search:  l.search.snapshot(),
+	}
+}
+
+type latencyHistogram struct {
+	counts [latencyBuckets]atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+// since records the time passed since start, read from clock.
+func (h *latencyHistogram) since(clock Clock, start time.Time) {
+	d := max(clock.Now().Sub(start), 0)
+	h.counts[latencyBucket(uint64(d))].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	s := LatencyHistogram{
+		Count: h.count.Load(),
+		Sum:   time.Duration(h.sum.Load()),
+	}
+
+	for i := range h.counts {
+		if n := h.counts[i].Load(); n > 0 {
+			s.Buckets = append(s.Buckets, LatencyBucket{UpperBound: latencyBound(i), Count: n})
+		}
+	}
+
+	return s
+}
+
+// latencyBucket returns the bucket counting ns nanoseconds. Values below
+// latencySub get a bucket each; larger ones are bucketed by their highest
+// bit and the latencySubBits bits below it.
+func latencyBucket(ns uint64) int {
+	if ns < latencySub {
+		return int(ns)
+	}
+
+	exp := bits.Len64(ns) - 1
+	mantissa := int(ns>>(exp-latencySubBits)) & (latencySub - 1)
+	return (exp-latencySubBits+1)*latencySub + mantissa
+}
+
+// latencyBound returns the exclusive upper bound of bucket i.
+func latencyBound(i int) time.Duration {
+	if i < latencySub {
+		return time.Duration(i + 1)
+	}
+
+	exp := i/latencySub - 1 + latencySubBits
+	mantissa := i % latencySub
+	upper := uint64(latencySub+mantissa+1) << (exp - latencySubBits)
+	if upper == 0 || upper > 1<<63-1 {
+		return time.Duration(1<<63 - 1)
+	}
+
+	return time.Duration(upper)
+}
@@ -0,0 +1,54 @@
+package prb
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger reports overwrites, rejected inserts and broken ring invariants
+// to logger.
+func WithLogger[T comparable](logger *slog.Logger) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.logger = logger
+	}
+}
+
+func (b *PriorityRingBuffer[T]) logEvict(evicted, incoming Element[T]) {
+	if b.logger == nil {
+		return
+	}
+
+	b.logger.LogAttrs(context.Background(), slog.LevelWarn, "prb: overwrote element",
+		slog.Int("evicted_priority", evicted.Priority),
+		slog.Int64("evicted_insertion_order", evicted.InsertionOrder),
+		slog.Int("priority", incoming.Priority),
+		slog.Int("capacity", b.capacity),
+	)
+}
+
+func (b *PriorityRingBuffer[T]) logReject(msg string, rejected Element[T]) {
+	if b.logger == nil {
+		return
+	}
+
+	b.logger.LogAttrs(context.Background(), slog.LevelWarn, msg,
+		slog.Int("priority", rejected.Priority),
+		slog.Int64("insertion_order", rejected.InsertionOrder),
+		slog.Int("size", b.size),
+	)
+}
+
+func (b *PriorityRingBuffer[T]) checkInvariants() {
+	if b.logger == nil {
+		return
+	}
+
+	if b.size < 0 || b.size > b.capacity || b.wrap(b.head+b.size) != b.tail {
+		b.logger.LogAttrs(context.Background(), slog.LevelError, "prb: ring invariant violated",
+			slog.Int("head", b.head),
+			slog.Int("tail", b.tail),
+			slog.Int("size", b.size),
+			slog.Int("capacity", b.capacity),
+		)
+	}
+}
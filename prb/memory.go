@@ -0,0 +1,66 @@
+package prb
+
+import "unsafe"
+
+// Sizer is implemented by values that own memory outside the element they
+// are stored in, such as pointed-to structs or slices. Size returns the
+// number of those extra bytes.
+type Sizer interface {
+	Size() int64
+}
+
+// Rough per-entry overhead of a Go map, on top of the key and value.
+const mapEntryOverhead = 16
+
+// MemoryUsage estimates the bytes of heap memory the buffer holds: the
+// backing array, the max index, key index, tags, keys, in-flight deliveries
+// and the published ReadView. String values count their bytes and values
+// implementing Sizer add what Size reports; other memory referenced by
+// values is not seen. Memory-mapped storage and the write-ahead log are not
+// included.
+func (b *PriorityRingBuffer[T]) MemoryUsage() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	elementSize := int64(unsafe.Sizeof(Element[T]{}))
+	intSize := int64(unsafe.Sizeof(0))
+
+	total := int64(cap(b.elements))*elementSize +
+		int64(cap(b.heap)+cap(b.heapPos))*intSize
+	b.each(func(_, slot int) bool {
+		total += b.elementExtra(b.elements[slot])
+		return true
+	})
+
+	for key := range b.keys {
+		total += int64(len(key)) + int64(unsafe.Sizeof(key)) + intSize + mapEntryOverhead
+	}
+
+	for _, f := range b.deliveries {
+		total += int64(unsafe.Sizeof(*f)) + elementSize + b.elementExtra(f.element) + mapEntryOverhead
+	}
+
+	if view := b.view.Load(); view != nil {
+		total += int64(cap(view.elements)) * elementSize
+	}
+
+	return total
+}
+
+// elementExtra estimates the bytes e references outside its slot. Keys are
+// shared with the key index and counted there.
+func (b *PriorityRingBuffer[T]) elementExtra(e Element[T]) int64 {
+	var extra int64
+	switch v := any(e.Value).(type) {
+	case string:
+		extra += int64(len(v))
+	case Sizer:
+		extra += v.Size()
+	}
+
+	for k, v := range e.Tags {
+		extra += int64(len(k)+len(v)) + 2*int64(unsafe.Sizeof(k)) + mapEntryOverhead
+	}
+
+	return extra
+}
@@ -0,0 +1,224 @@
+package prb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+var (
+	ErrNotFixedSize     = errors.New("element type has no fixed-size binary encoding")
+	ErrMmapUnsupported  = errors.New("memory-mapped buffers are not supported on this platform")
+	ErrMmapLayoutChange = errors.New("mapped file was created for a different element layout")
+	ErrMmapMetadata     = errors.New("memory-mapped buffers cannot store keys or tags")
+	ErrMmapResize       = errors.New("memory-mapped buffers cannot be resized")
+)
+
+const (
+	mmapMagic      = "GPRBMMAP"
+	mmapVersion    = 1
+	mmapHeaderSize = 128
+)
+
+// slotStore mirrors ring slots and pointers into durable storage.
+type slotStore[T comparable] interface {
+	storeSlot(index int, element Element[T])
+	storeHeader(b *PriorityRingBuffer[T])
+	sync() error
+	close() error
+}
+
+// mmapStore lays out a 128 byte header followed by capacity fixed-size slots
+// of priority, insertion order and the binary encoded value.
+type mmapStore[T comparable] struct {
+	file     *os.File
+	data     []byte
+	slotSize int
+}
+
+// NewMmap creates a buffer whose slots are mirrored into the file at path
+// through a shared memory mapping, so its contents survive restarts without
+// explicit serialization. T must have a fixed-size encoding/binary
+// representation. An existing file is reopened with the configuration it was
+// created with.
+func NewMmap[T comparable](path string, capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
+	b, err := New(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var zero T
+	valueSize := binary.Size(zero)
+	if valueSize <= 0 {
+		return nil, ErrNotFixedSize
+	}
+	slotSize := 16 + valueSize
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	existing := info.Size() > 0
+	if !existing {
+		if err := file.Truncate(int64(mmapHeaderSize + capacity*slotSize)); err != nil {
+			file.Close()
+			return nil, err
+		}
+		info, err = file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	if info.Size() < mmapHeaderSize {
+		file.Close()
+		return nil, ErrInvalidFormat
+	}
+
+	data, err := mapFile(file, int(info.Size()))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	store := &mmapStore[T]{file: file, data: data, slotSize: slotSize}
+	if existing {
+		err = store.restore(b)
+	} else {
+		copy(data, mmapMagic)
+		data[8] = mmapVersion
+		store.storeHeader(b)
+	}
+
+	if err != nil {
+		store.close()
+		return nil, err
+	}
+
+	b.store = store
+	return b, nil
+}
+
+func (s *mmapStore[T]) restore(b *PriorityRingBuffer[T]) error {
+	if !bytes.Equal(s.data[:8], []byte(mmapMagic)) {
+		return ErrInvalidFormat
+	}
+	if s.data[8] != mmapVersion {
+		return ErrUnsupportedVersion
+	}
+
+	le := binary.LittleEndian
+	capacity := int(le.Uint64(s.data[16:]))
+	if int(le.Uint64(s.data[32:])) != s.slotSize {
+		return ErrMmapLayoutChange
+	}
+	if capacity <= 0 || len(s.data) < mmapHeaderSize+capacity*s.slotSize {
+		return ErrInvalidFormat
+	}
+
+	state := Snapshot[T]{
+		Capacity:     capacity,
+		BubbleWindow: int(le.Uint64(s.data[24:])),
+		OverflowMode: OverflowMode(s.data[9]),
+		OrderCounter: int64(le.Uint64(s.data[64:])),
+	}
+	for _, q := range b.quotas {
+		state.Quotas = append(state.Quotas, q.Quota)
+	}
+
+	head := int(le.Uint64(s.data[40:]))
+	size := int(le.Uint64(s.data[56:]))
+	if head < 0 || head >= capacity || size < 0 || size > capacity {
+		return ErrInvalidState
+	}
+
+	for i := 0; i < size; i++ {
+		e, err := s.loadSlot((head + i) % capacity)
+		if err != nil {
+			return err
+		}
+		state.Elements = append(state.Elements, e)
+	}
+
+	if err := b.load(state); err != nil {
+		return err
+	}
+
+	// load lays elements out from slot zero; rewrite the file to match.
+	for i := 0; i < b.size; i++ {
+		s.storeSlot(i, b.elements[i])
+	}
+	s.storeHeader(b)
+
+	return nil
+}
+
+func (s *mmapStore[T]) slot(index int) []byte {
+	offset := mmapHeaderSize + index*s.slotSize
+	return s.data[offset : offset+s.slotSize]
+}
+
+func (s *mmapStore[T]) storeSlot(index int, element Element[T]) {
+	slot := s.slot(index)
+	binary.LittleEndian.PutUint64(slot, uint64(element.Priority))
+	binary.LittleEndian.PutUint64(slot[8:], uint64(element.InsertionOrder))
+	_, _ = binary.Encode(slot[16:], binary.LittleEndian, element.Value)
+}
+
+func (s *mmapStore[T]) loadSlot(index int) (Element[T], error) {
+	slot := s.slot(index)
+	e := Element[T]{
+		Priority:       int(int64(binary.LittleEndian.Uint64(slot))),
+		InsertionOrder: int64(binary.LittleEndian.Uint64(slot[8:])),
+	}
+
+	_, err := binary.Decode(slot[16:], binary.LittleEndian, &e.Value)
+	return e, err
+}
+
+func (s *mmapStore[T]) storeHeader(b *PriorityRingBuffer[T]) {
+	le := binary.LittleEndian
+	s.data[9] = byte(b.overflow)
+	le.PutUint64(s.data[16:], uint64(b.capacity))
+	le.PutUint64(s.data[24:], uint64(b.bubbleWindow))
+	le.PutUint64(s.data[32:], uint64(s.slotSize))
+	le.PutUint64(s.data[40:], uint64(b.head))
+	le.PutUint64(s.data[48:], uint64(b.tail))
+	le.PutUint64(s.data[56:], uint64(b.size))
+	le.PutUint64(s.data[64:], uint64(b.orderCounter))
+}
+
+func (s *mmapStore[T]) sync() error {
+	return s.file.Sync()
+}
+
+func (s *mmapStore[T]) close() error {
+	err := unmapFile(s.data)
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// Sync flushes a memory-mapped buffer to disk. It is a no-op for other
+// buffers.
+func (b *PriorityRingBuffer[T]) Sync() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.store == nil {
+		return nil
+	}
+
+	return b.store.sync()
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package prb
+
+import "os"
+
+func mapFile(*os.File, int) ([]byte, error) {
+	return nil, ErrMmapUnsupported
+}
+
+func unmapFile([]byte) error {
+	return ErrMmapUnsupported
+}
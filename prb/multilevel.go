@@ -0,0 +1,263 @@
+package prb
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrInvalidLevels   = errors.New("levels must be non-empty and unique")
+	ErrInvalidWeights  = errors.New("weights must be positive and cover every level")
+	ErrUnknownPriority = errors.New("priority does not match any configured level")
+)
+
+// MultiLevelPRB keeps one FIFO ring per priority level and always serves the
+// highest non-empty level first, so ordering is exact rather than windowed.
+type MultiLevelPRB[T comparable] struct {
+	levels       []int
+	rings        []*PriorityRingBuffer[T]
+	ringOpts     []Option[T]
+	weights      map[int]int
+	credits      []int
+	orderCounter int64
+	mu           sync.RWMutex
+}
+
+type MultiLevelOption[T comparable] func(*MultiLevelPRB[T])
+
+// WithLevelOptions applies opts to every per-level ring. The rings must not
+// use Block.
+func WithLevelOptions[T comparable](opts ...Option[T]) MultiLevelOption[T] {
+	return func(m *MultiLevelPRB[T]) {
+		m.ringOpts = append(m.ringOpts, opts...)
+	}
+}
+
+// WithLevelWeights switches Dequeue from strict level ordering to smooth
+// weighted round-robin, serving each non-empty level in proportion to its
+// weight.
+func WithLevelWeights[T comparable](weights map[int]int) MultiLevelOption[T] {
+	return func(m *MultiLevelPRB[T]) {
+		m.weights = weights
+	}
+}
+
+func NewMultiLevel[T comparable](capacityPerLevel int, levels []int, opts ...MultiLevelOption[T]) (*MultiLevelPRB[T], error) {
+	if capacityPerLevel <= 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	sorted := slices.Clone(levels)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+	if len(sorted) == 0 || len(slices.Compact(slices.Clone(sorted))) != len(sorted) {
+		return nil, ErrInvalidLevels
+	}
+
+	m := &MultiLevelPRB[T]{
+		levels: sorted,
+		rings:  make([]*PriorityRingBuffer[T], len(sorted)),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.weights != nil {
+		for _, level := range m.levels {
+			if m.weights[level] <= 0 {
+				return nil, ErrInvalidWeights
+			}
+		}
+		m.credits = make([]int, len(m.levels))
+	}
+
+	for i := range m.rings {
+		ring, err := New(capacityPerLevel, m.ringOpts...)
+		if err != nil {
+			return nil, err
+		}
+		// Insert holds the level lock, so a blocked insert would keep
+		// Dequeue from ever making room.
+		if ring.OverflowMode() == Block {
+			return nil, ErrInvalidOverflowMode
+		}
+		m.rings[i] = ring
+	}
+
+	return m, nil
+}
+
+func (m *MultiLevelPRB[T]) ring(priority int) *PriorityRingBuffer[T] {
+	for i, level := range m.levels {
+		if level == priority {
+			return m.rings[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *MultiLevelPRB[T]) Insert(value T, priority int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ring := m.ring(priority)
+	if ring == nil {
+		return ErrUnknownPriority
+	}
+
+	element := Element[T]{
+		Value:          value,
+		Priority:       priority,
+		InsertionOrder: m.orderCounter,
+		keepOrder:      true,
+	}
+	m.orderCounter++
+
+	_, _, err := ring.insert(element, nil, nil)
+	return err
+}
+
+func (m *MultiLevelPRB[T]) Dequeue() (Element[T], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := m.next(true)
+	if next < 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	return m.rings[next].Dequeue()
+}
+
+func (m *MultiLevelPRB[T]) Peek() (Element[T], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	next := m.next(false)
+	if next < 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	return m.rings[next].Peek()
+}
+
+// next returns the index of the ring to serve, or -1 when all are empty.
+// With weights configured, commit advances the round-robin credits.
+func (m *MultiLevelPRB[T]) next(commit bool) int {
+	if m.weights == nil {
+		for i, ring := range m.rings {
+			if !ring.IsEmpty() {
+				return i
+			}
+		}
+		return -1
+	}
+
+	best, total := -1, 0
+	bestCredit := 0
+	for i, ring := range m.rings {
+		if ring.IsEmpty() {
+			continue
+		}
+
+		weight := m.weights[m.levels[i]]
+		total += weight
+		credit := m.credits[i] + weight
+		if best < 0 || credit > bestCredit {
+			best, bestCredit = i, credit
+		}
+	}
+
+	if commit && best >= 0 {
+		for i, ring := range m.rings {
+			if !ring.IsEmpty() {
+				m.credits[i] += m.weights[m.levels[i]]
+			}
+		}
+		m.credits[best] -= total
+	}
+
+	return best
+}
+
+func (m *MultiLevelPRB[T]) PeekMaxPriority() (Element[T], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, ring := range m.rings {
+		if element, err := ring.Peek(); err == nil {
+			return element, nil
+		}
+	}
+
+	return Element[T]{}, ErrBufferEmpty
+}
+
+func (m *MultiLevelPRB[T]) Levels() []int {
+	return slices.Clone(m.levels)
+}
+
+func (m *MultiLevelPRB[T]) LevelLen(priority int) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ring := m.ring(priority)
+	if ring == nil {
+		return 0
+	}
+
+	return ring.Len()
+}
+
+func (m *MultiLevelPRB[T]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := 0
+	for _, ring := range m.rings {
+		total += ring.Len()
+	}
+
+	return total
+}
+
+func (m *MultiLevelPRB[T]) Cap() int {
+	return len(m.rings) * m.rings[0].Cap()
+}
+
+func (m *MultiLevelPRB[T]) IsEmpty() bool {
+	return m.Len() == 0
+}
+
+func (m *MultiLevelPRB[T]) Snapshot() []Element[T] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []Element[T]
+	for _, ring := range m.rings {
+		result = append(result, ring.Snapshot()...)
+	}
+
+	return result
+}
+
+func (m *MultiLevelPRB[T]) Clear() {
+	_ = m.ClearErr()
+}
+
+// ClearErr is like Clear but returns the errors of the level rings' ClearErr.
+func (m *MultiLevelPRB[T]) ClearErr() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, ring := range m.rings {
+		errs = append(errs, ring.ClearErr())
+	}
+	clear(m.credits)
+
+	return errors.Join(errs...)
+}
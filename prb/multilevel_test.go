@@ -0,0 +1,41 @@
+package prb_test
+
+import (
+	"errors"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestMultiLevelOverflowModes(t *testing.T) {
+	tests := []struct {
+		mode prb.OverflowMode
+		want error
+	}{
+		{prb.DropOldest, nil},
+		{prb.Reject, nil},
+		{prb.DropNewest, nil},
+		{prb.DropLowestPriority, nil},
+		{prb.Block, prb.ErrInvalidOverflowMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			m, err := prb.NewMultiLevel[int](2, []int{1, 2},
+				prb.WithLevelOptions[int](prb.WithOverflowMode[int](tt.mode)))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("NewMultiLevel: %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			for i := range 3 {
+				_ = m.Insert(i, 1)
+			}
+			if got := m.LevelLen(1); got != 2 {
+				t.Fatalf("LevelLen(1) = %d, want 2", got)
+			}
+		})
+	}
+}
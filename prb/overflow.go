@@ -0,0 +1,192 @@
+package prb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var ErrInvalidOverflowMode = errors.New("unknown overflow mode")
+
+// OverflowMode decides what Insert does when the buffer is full. DropOldest
+// and Reject encode as 0 and 1, the values of the former overwrite guard
+// flag.
+type OverflowMode uint8
+
+const (
+	// DropOldest evicts the head element.
+	DropOldest OverflowMode = iota
+	// Reject evicts the head only if the incoming element has a higher
+	// priority and otherwise fails with *FullError.
+	Reject
+	// DropNewest always fails with *FullError, keeping the contents.
+	DropNewest
+	// DropLowestPriority evicts the lowest priority element, the oldest among
+	// equals, unless the incoming element has an even lower priority, in
+	// which case it fails with *FullError.
+	DropLowestPriority
+	// Block makes Insert wait until an element is removed. Sharded,
+	// multi-level and tiered buffers, which cannot wait on a single ring,
+	// fail with ErrInvalidOverflowMode if their rings use it.
+	Block
+	// Spill appends inserts into a full buffer to a file in the WithSpillDir
+	// directory and moves them back into the ring, oldest first, as it
+	// drains, so memory stays bounded while nothing is dropped. Spilled
+	// elements only compete on priority once they are back in the ring, are
+	// not part of snapshots, and are deleted with the file by Close; a
+	// write-ahead log still recovers them.
+	Spill
+)
+
+var overflowModeNames = []string{"dropOldest", "reject", "dropNewest", "dropLowestPriority", "block", "spill"}
+
+func (m OverflowMode) String() string {
+	if int(m) < len(overflowModeNames) {
+		return overflowModeNames[m]
+	}
+
+	return fmt.Sprintf("OverflowMode(%d)", uint8(m))
+}
+
+func (m OverflowMode) MarshalText() ([]byte, error) {
+	if int(m) >= len(overflowModeNames) {
+		return nil, ErrInvalidOverflowMode
+	}
+
+	return []byte(overflowModeNames[m]), nil
+}
+
+func (m *OverflowMode) UnmarshalText(text []byte) error {
+	i := slices.Index(overflowModeNames, string(text))
+	if i < 0 {
+		return ErrInvalidOverflowMode
+	}
+
+	*m = OverflowMode(i)
+	return nil
+}
+
+func WithOverflowMode[T comparable](mode OverflowMode) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.overflow = mode
+	}
+}
+
+// OverflowMode returns the mode Insert uses when the buffer is full.
+func (b *PriorityRingBuffer[T]) OverflowMode() OverflowMode {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.overflow
+}
+
+// SetOverflowMode switches the mode Insert uses when the buffer is full,
+// keeping the queued elements, for example to shed load with DropOldest
+// during an incident. Inserts blocked under Block retry with the new mode.
+func (b *PriorityRingBuffer[T]) SetOverflowMode(mode OverflowMode) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if mode > Spill {
+		return ErrInvalidOverflowMode
+	}
+
+	if err := b.logSetOverflowMode(mode); err != nil {
+		return err
+	}
+
+	b.overflow = mode
+	b.commit()
+	return nil
+}
+
+// overflowVictim returns the slot of the element the overflow mode evicts to make room for element in a full buffer, or the
+// error element is rejected with.
+func (b *PriorityRingBuffer[T]) overflowVictim(element Element[T]) (int, error) {
+	head := b.elements[b.head]
+
+	switch b.overflow {
+	case DropOldest:
+		return b.head, nil
+	case Reject:
+		if element.Priority > head.Priority {
+			return b.head, nil
+		}
+	case DropLowestPriority:
+		victim := b.minSlot()
+		if element.Priority >= b.elements[victim].Priority {
+			return victim, nil
+		}
+	}
+
+	return -1, &FullError{
+		Priority:           element.Priority,
+		HeadPriority:       head.Priority,
+		HeadInsertionOrder: head.InsertionOrder,
+	}
+}
+
+// minSlot returns the slot of the lowest priority element, the oldest among
+// equals. The buffer must not be empty.
+func (b *PriorityRingBuffer[T]) minSlot() int {
+	minimum := b.head
+	for i := 1; i < b.size; i++ {
+		candidate, current := b.elements[b.wrap(b.head+i)], b.elements[minimum]
+		if candidate.Priority < current.Priority ||
+			(candidate.Priority == current.Priority && candidate.InsertionOrder < current.InsertionOrder) {
+			minimum = b.wrap(b.head + i)
+		}
+	}
+
+	return minimum
+}
+
+// wait releases the write lock until ready reports true, the buffer is
+// closed, deadline fires or done is closed, whichever comes first. Nil
+// channels never fire. The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) wait(ready func() bool, deadline <-chan time.Time, done <-chan struct{}) error {
+	for !b.closed && !ready() {
+		w := make(chan struct{}, 1)
+		b.watchers = append(b.watchers, w)
+
+		b.mu.Unlock()
+		expired := false
+		select {
+		case <-w:
+		case <-deadline:
+			expired = true
+		case <-done:
+			expired = true
+		}
+		b.mu.Lock()
+
+		b.watchers = slices.DeleteFunc(b.watchers, func(c chan struct{}) bool {
+			return c == w
+		})
+		if expired {
+			break
+		}
+	}
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	return nil
+}
+
+// accepts reports whether an element with priority can be inserted now
+// without being rejected by the overflow mode.
+func (b *PriorityRingBuffer[T]) accepts(priority int) bool {
+	if b.size < b.capacity || b.canGrow() || b.overflow == Spill {
+		return true
+	}
+
+	_, err := b.overflowVictim(Element[T]{Priority: priority})
+	return err == nil
+}
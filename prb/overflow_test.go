@@ -0,0 +1,110 @@
+package prb_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoPRB/prb"
+)
+
+func TestOverflowModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        prb.OverflowMode
+		priority    int
+		wantEvicted string
+		wantFull    bool
+	}{
+		{"drop oldest", prb.DropOldest, 0, "a", false},
+		{"reject lower", prb.Reject, 0, "", true},
+		{"reject higher", prb.Reject, 9, "a", false},
+		{"drop newest", prb.DropNewest, 9, "", true},
+		{"drop lowest", prb.DropLowestPriority, 2, "b", false},
+		{"drop lowest equal", prb.DropLowestPriority, 1, "b", false},
+		{"drop lowest below", prb.DropLowestPriority, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := prb.MustNew[string](3, prb.WithOverflowMode[string](tt.mode))
+			for _, e := range []struct {
+				value    string
+				priority int
+			}{{"a", 5}, {"b", 1}, {"c", 3}} {
+				if err := b.Insert(e.value, e.priority); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			evicted, didEvict, err := b.InsertEvict("d", tt.priority)
+			var full *prb.FullError
+			if got := errors.As(err, &full); got != tt.wantFull {
+				t.Fatalf("InsertEvict: %v, want full %v", err, tt.wantFull)
+			}
+			if didEvict != (tt.wantEvicted != "") || evicted.Value != tt.wantEvicted {
+				t.Fatalf("evicted %q (%v), want %q", evicted.Value, didEvict, tt.wantEvicted)
+			}
+			if got := b.Len(); got != 3 {
+				t.Fatalf("Len() = %d, want 3", got)
+			}
+			if err := b.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	b := prb.MustNew[int](1, prb.WithOverflowMode[int](prb.Block))
+	if err := b.Insert(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var full *prb.FullError
+	if err := b.InsertTimeout(2, 2, time.Millisecond); !errors.As(err, &full) {
+		t.Fatalf("InsertTimeout: %v, want *FullError", err)
+	}
+
+	inserted := make(chan error)
+	go func() { inserted <- b.Insert(3, 3) }()
+	select {
+	case err := <-inserted:
+		t.Fatalf("Insert returned %v before a dequeue", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if e, err := b.Dequeue(); err != nil || e.Value != 1 {
+		t.Fatalf("Dequeue() = %v, %v, want 1", e.Value, err)
+	}
+	if err := <-inserted; err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if e, err := b.Dequeue(); err != nil || e.Value != 3 {
+		t.Fatalf("Dequeue() = %v, %v, want 3", e.Value, err)
+	}
+}
+
+func TestSetOverflowModeWakesBlocked(t *testing.T) {
+	b := prb.MustNew[int](1, prb.WithOverflowMode[int](prb.Block))
+	if err := b.Insert(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	inserted := make(chan error)
+	go func() { inserted <- b.Insert(2, 2) }()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := b.SetOverflowMode(prb.DropOldest); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-inserted; err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if e, err := b.Peek(); err != nil || e.Value != 2 {
+		t.Fatalf("Peek() = %v, %v, want 2", e.Value, err)
+	}
+	if err := b.SetOverflowMode(prb.Spill + 1); !errors.Is(err, prb.ErrInvalidOverflowMode) {
+		t.Fatalf("SetOverflowMode: %v, want ErrInvalidOverflowMode", err)
+	}
+}
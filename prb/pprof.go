@@ -0,0 +1,52 @@
+package prb
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// WithProfileLabels names the buffer in CPU and goroutine profiles. Its
+// background goroutines, which are always labelled with their role under
+// the "prb" key, also get "prb.buffer" set to name, and inserts and dequeues
+// acquire the lock under the labels prb=lock and prb.buffer=name, so time
+// spent contending for it is attributed to the buffer.
+//
+// pprof cannot read a goroutine's current labels, so a goroutine that sets
+// its own labels loses them when it inserts into or dequeues from a buffer
+// with this option.
+func WithProfileLabels[T comparable](name string) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.profileName = name
+	}
+}
+
+// labels returns the profiler labels for a goroutine of the buffer with the
+// given role.
+func (b *PriorityRingBuffer[T]) labels(role string) pprof.LabelSet {
+	if b.profileName == "" {
+		return pprof.Labels("prb", role)
+	}
+
+	return pprof.Labels("prb", role, "prb.buffer", b.profileName)
+}
+
+// goLabelled runs fn on a new goroutine labelled with role.
+func (b *PriorityRingBuffer[T]) goLabelled(role string, fn func()) {
+	labels := b.labels(role)
+	go pprof.Do(context.Background(), labels, func(context.Context) {
+		fn()
+	})
+}
+
+// lock acquires the write lock, labelled for profiling if the buffer was
+// created with WithProfileLabels.
+func (b *PriorityRingBuffer[T]) lock() {
+	if b.profileName == "" {
+		b.mu.Lock()
+		return
+	}
+
+	pprof.Do(context.Background(), b.labels("lock"), func(context.Context) {
+		b.mu.Lock()
+	})
+}
@@ -1,32 +1,148 @@
 package prb
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	ErrInvalidCapacity = errors.New("capacity must be positive")
 	ErrInvalidWindow   = errors.New("bubbleWindow must be zero or positive and less than capacity")
 	ErrBufferEmpty     = errors.New("buffer is empty")
-	ErrBufferFull      = errors.New("buffer is full, refused to overwrite higher priority element")
+	ErrBufferFull      = errors.New("buffer is full")
+	ErrInvalidQuota    = errors.New("quota must have minPriority <= maxPriority and a non-negative limit")
+	ErrQuotaExceeded   = errors.New("priority quota exceeded")
+	ErrIndexOutOfRange = errors.New("index out of range")
+	ErrNotFound        = errors.New("element not found")
+	ErrClosed          = errors.New("buffer is closed")
 )
 
+// FullError is returned when the overflow mode rejects an insert into a full
+// buffer. It matches ErrBufferFull with errors.Is.
+type FullError struct {
+	Priority           int
+	HeadPriority       int
+	HeadInsertionOrder int64
+}
+
+func (e *FullError) Error() string {
+	return fmt.Sprintf("%v: rejected priority %d, head priority %d", ErrBufferFull, e.Priority, e.HeadPriority)
+}
+
+func (e *FullError) Unwrap() error {
+	return ErrBufferFull
+}
+
 type Element[T comparable] struct {
 	Value          T
 	Priority       int
 	InsertionOrder int64
+	GuaranteedMax  bool
+	// Tags holds optional caller metadata, such as trace IDs, set by
+	// InsertWithMeta. It is shared with the buffer and must not be modified.
+	Tags map[string]string `json:",omitempty"`
+	// Key identifies elements added by InsertKeyed; it is empty otherwise.
+	Key string `json:",omitempty"`
+
+	inserted time.Time
+	attempts int
+	// keepOrder makes insert keep InsertionOrder instead of assigning one,
+	// for wrappers that share one order across several buffers.
+	keepOrder bool
 }
 
 type PriorityRingBuffer[T comparable] struct {
-	elements       []Element[T]
-	capacity       int
-	head, tail     int
-	size           int
-	bubbleWindow   int
-	orderCounter   int64
-	overwriteGuard bool
-	mu             sync.RWMutex
+	elements     []Element[T]
+	capacity     int
+	head, tail   int
+	size         int
+	bubbleWindow int
+	orderCounter int64
+	overflow     OverflowMode
+	unordered    bool
+	sorted       bool
+	closed       bool
+	quotas       []quota
+	codec        Codec[T]
+	compressor   Compressor
+	keyProvider  KeyProvider
+	keyErr       error
+	partial      bool
+	onCorruption func(*CorruptionError)
+	wal          *wal
+	walSync      SyncPolicy
+	store        slotStore[T]
+	version      uint64
+	watchers     []chan struct{}
+	counters     counters
+	clock        Clock
+	limiter      *limiter
+	watermarks   *watermarks
+	adaptive     *adaptiveWindow
+	decay        DecayFunc
+	keys         map[string]int
+	deliveries   map[uint64]*inflight[T]
+	deliveryID   uint64
+	ackTimeout   time.Duration
+	maxAttempts  int
+	deadLetter   func(Element[T], error)
+	dead         []deadLetter[T]
+	replicas     []*replica
+	tracer       Tracer
+	latency      *latencies
+	profileName  string
+	logger       *slog.Logger
+	events       chan Event[T]
+	eventBuffer  int
+	view         atomic.Pointer[ReadView[T]]
+	viewBatch    uint64
+	maxIndex     bool
+	heap         []int
+	heapPos      []int
+	maxCache     bool
+	cachedMax    atomic.Int64
+	roundUp      bool
+	autoGrow     bool
+	maxCapacity  int
+	spillDir     string
+	secondary    *PriorityRingBuffer[T]
+	spill        *spill
+	refilling    bool
+	pow2         bool
+	opts         []Option[T]
+	mu           sync.RWMutex
+}
+
+type Quota struct {
+	MinPriority int
+	MaxPriority int
+	Limit       int
+}
+
+type quota struct {
+	Quota
+	used int
+}
+
+func (q *quota) covers(priority int) bool {
+	return priority >= q.MinPriority && priority <= q.MaxPriority
+}
+
+// PriorityBuffer is the method set shared by every buffer implementation.
+type PriorityBuffer[T comparable] interface {
+	Insert(value T, priority int) error
+	Dequeue() (Element[T], error)
+	Peek() (Element[T], error)
+	Len() int
+	Cap() int
 }
 
 type Option[T comparable] func(*PriorityRingBuffer[T])
@@ -37,20 +153,79 @@ func WithBubbleWindow[T comparable](window int) Option[T] {
 	}
 }
 
+// WithOverwriteGuard selects Reject when guard is set and DropOldest
+// otherwise.
 func WithOverwriteGuard[T comparable](guard bool) Option[T] {
 	return func(b *PriorityRingBuffer[T]) {
-		b.overwriteGuard = guard
+		b.overflow = DropOldest
+		if guard {
+			b.overflow = Reject
+		}
+	}
+}
+
+// WithQuota limits how many slots elements with a priority within
+// [minPriority, maxPriority] may occupy at once.
+func WithQuota[T comparable](minPriority, maxPriority, limit int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.quotas = append(b.quotas, quota{Quota: Quota{
+			MinPriority: minPriority,
+			MaxPriority: maxPriority,
+			Limit:       limit,
+		}})
+	}
+}
+
+// WithRoundCapacityUp rounds the capacity up to the next power of two so
+// ring index arithmetic can use a bitmask instead of a division.
+func WithRoundCapacityUp[T comparable]() Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.roundUp = true
 	}
 }
 
 func New[T comparable](capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
-	if capacity <= 0 {
-		return nil, ErrInvalidCapacity
+	b, err := configure(capacity, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	b.elements = make([]Element[T], b.capacity)
+	b.pow2 = b.capacity&(b.capacity-1) == 0
+	b.sorted = b.wantsHeap()
+
+	if b.viewBatch > 0 {
+		b.publishView()
 	}
 
+	b.heapReset()
+	b.cachedMax.Store(-1)
+	return b, nil
+}
+
+// MustNew is like New but panics if the configuration is invalid. It
+// simplifies initializing package-level variables and tests.
+func MustNew[T comparable](capacity int, opts ...Option[T]) *PriorityRingBuffer[T] {
+	return Must(New(capacity, opts...))
+}
+
+// Must returns v, or panics if err is not nil. It wraps constructors such as
+// NewSharded or NewMultiLevel the way MustNew wraps New.
+func Must[V any](v V, err error) V {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// configure applies opts to a buffer with capacity without allocating its
+// storage, and validates the result.
+func configure[T comparable](capacity int, opts []Option[T]) (*PriorityRingBuffer[T], error) {
 	b := &PriorityRingBuffer[T]{
-		elements: make([]Element[T], capacity),
 		capacity: capacity,
+		clock:    realClock{},
+		opts:     slices.Clone(opts),
 		mu:       sync.RWMutex{},
 	}
 
@@ -58,85 +233,791 @@ func New[T comparable](capacity int, opts ...Option[T]) (*PriorityRingBuffer[T],
 		opt(b)
 	}
 
-	if b.bubbleWindow < 0 || b.bubbleWindow > capacity-1 {
-		return nil, ErrInvalidWindow
+	if b.roundUp && b.capacity > 0 {
+		b.capacity = 1 << bits.Len(uint(b.capacity-1))
+	}
+
+	if err := b.validateConfig(); err != nil {
+		return nil, err
 	}
 
 	return b, nil
 }
 
+// validateConfig reports every problem with the configuration. A single
+// problem is returned as is, several are joined with errors.Join.
+func (b *PriorityRingBuffer[T]) validateConfig() error {
+	var errs []error
+	if b.capacity <= 0 {
+		errs = append(errs, ErrInvalidCapacity)
+	} else if b.bubbleWindow < 0 || b.bubbleWindow > b.capacity-1 {
+		errs = append(errs, ErrInvalidWindow)
+	}
+
+	if b.overflow > Spill {
+		errs = append(errs, ErrInvalidOverflowMode)
+	}
+
+	if !b.validSecondary() {
+		errs = append(errs, ErrInvalidOverflowBuffer)
+	}
+
+	if b.autoGrow && (b.maxCapacity < 0 || (b.maxCapacity > 0 && b.maxCapacity < b.capacity)) {
+		errs = append(errs, fmt.Errorf("auto grow limit %d: %w", b.maxCapacity, ErrInvalidCapacity))
+	}
+
+	if b.limiter != nil && !b.limiter.valid() {
+		errs = append(errs, ErrInvalidRateLimit)
+	}
+
+	if b.watermarks != nil && !b.watermarks.valid() {
+		errs = append(errs, ErrInvalidWatermarks)
+	}
+
+	if a := b.adaptive; a != nil && (a.min < 0 || a.min > a.max || (b.capacity > 0 && a.max > b.capacity-1)) {
+		errs = append(errs, fmt.Errorf("adaptive window: %w", ErrInvalidWindow))
+	}
+
+	if b.keyErr != nil {
+		errs = append(errs, fmt.Errorf("encryption key: %w", b.keyErr))
+	}
+
+	for _, q := range b.quotas {
+		if q.MinPriority > q.MaxPriority || q.Limit < 0 {
+			errs = append(errs, fmt.Errorf("quota [%d, %d] limit %d: %w", q.MinPriority, q.MaxPriority, q.Limit, ErrInvalidQuota))
+		}
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return errors.Join(errs...)
+}
+
 func (b *PriorityRingBuffer[T]) Insert(value T, priority int) error {
-	b.mu.Lock()
+	_, _, err := b.InsertEvict(value, priority)
+	return err
+}
+
+// InsertEvict is Insert that also returns the element it overwrote, if the
+// buffer was full, so callers can reroute it.
+func (b *PriorityRingBuffer[T]) InsertEvict(value T, priority int) (Element[T], bool, error) {
+	return b.insert(Element[T]{Value: value, Priority: priority}, nil, nil)
+}
+
+// InsertTimeout is Insert that, when the overflow mode would reject or block
+// on a full buffer, waits up to d for room before giving up with the
+// rejection.
+func (b *PriorityRingBuffer[T]) InsertTimeout(value T, priority int, d time.Duration) error {
+	timer := b.clock.NewTimer(d)
+	defer timer.Stop()
+
+	_, _, err := b.insert(Element[T]{Value: value, Priority: priority}, timer.C(), nil)
+	return err
+}
+
+// InsertContext is InsertTimeout that waits for room until ctx is done.
+func (b *PriorityRingBuffer[T]) InsertContext(ctx context.Context, value T, priority int) error {
+	_, _, err := b.insert(Element[T]{Value: value, Priority: priority}, nil, ctx.Done())
+	return err
+}
+
+// insert waits for room until deadline fires or done is closed if either is
+// given, or the overflow mode is Block, then adds element, or replaces the
+// element with the same key. InsertionOrder is assigned here unless the
+// element is marked to keep its own.
+func (b *PriorityRingBuffer[T]) insert(element Element[T], deadline <-chan time.Time, done <-chan struct{}) (evicted Element[T], didEvict bool, err error) {
+	defer b.flushDead()
+	b.lock()
 	defer b.mu.Unlock()
 
-	element := Element[T]{
-		Value:          value,
-		Priority:       priority,
-		InsertionOrder: b.orderCounter,
+	if b.closed {
+		return Element[T]{}, false, ErrClosed
+	}
+
+	if (element.Tags != nil || element.Key != "") && b.store != nil {
+		return Element[T]{}, false, ErrMmapMetadata
+	}
+
+	priority := element.Priority
+	if b.tracer != nil {
+		start := b.clock.Now()
+		defer func() {
+			b.trace(TraceEvent{
+				Operation: "insert",
+				Start:     start,
+				Priority:  priority,
+				Overwrite: didEvict,
+				Err:       err,
+			})
+		}()
+	}
+
+	if b.overflow == Block || deadline != nil || done != nil {
+		ready := func() bool { return b.keySlot(element.Key) >= 0 || b.accepts(priority) }
+		if err := b.wait(ready, deadline, done); err != nil {
+			return Element[T]{}, false, err
+		}
 	}
-	b.orderCounter++
 
-	overwriting := b.size == b.capacity
-	insertIndex := b.tail
+	if b.latency != nil {
+		defer b.latency.insert.since(b.clock, b.clock.Now())
+	}
+
+	if b.limiter != nil && !b.limiter.allow(b.clock.Now()) {
+		b.counters.rateRejections++
+		b.logReject("prb: rate limit rejected insert", element)
+		b.emit(EventReject, element, ErrRateLimited)
+		return Element[T]{}, false, ErrRateLimited
+	}
 
-	if overwriting && b.overwriteGuard {
-		if priority <= b.elements[b.head].Priority {
-			return ErrBufferFull
+	if err := b.logInsert(element); err != nil {
+		return Element[T]{}, false, err
+	}
+
+	if slot := b.keySlot(element.Key); slot >= 0 {
+		if !b.quotaAllows(priority, slot) {
+			b.counters.quotaRejections++
+			b.logReject("prb: priority quota rejected insert", element)
+			b.emit(EventReject, element, ErrQuotaExceeded)
+			return Element[T]{}, false, ErrQuotaExceeded
 		}
+
+		old := b.take(slot)
+		b.emit(EventRemove, old, nil)
+		element.InsertionOrder = old.InsertionOrder
+	} else if element.keepOrder {
+		b.orderCounter = max(b.orderCounter, element.InsertionOrder+1)
+	} else {
+		element.InsertionOrder = b.orderCounter
+		b.orderCounter++
 	}
+	element.keepOrder = false
 
-	b.elements[insertIndex] = element
+	evicted, didEvict, err = b.push(element)
+	if err == nil && b.limiter != nil {
+		b.limiter.take()
+	}
 
-	b.bubbleElement(insertIndex)
+	return evicted, didEvict, err
+}
 
-	b.tail = (b.tail + 1) % b.capacity
-	if overwriting {
-		b.head = b.tail
+// push adds element, overwriting the head if the buffer is full, and returns
+// the overwritten element.
+func (b *PriorityRingBuffer[T]) push(element Element[T]) (Element[T], bool, error) {
+	priority := element.Priority
+	b.stamp(&element)
+	victim := -1
+	if b.size == b.capacity && b.canGrow() {
+		b.grow()
+	}
+	if b.size == b.capacity && b.overflowTo(element) {
+		return Element[T]{}, false, nil
+	}
+	if b.size == b.capacity && b.overflow == Spill {
+		if err := b.spillElement(element); err != nil {
+			return Element[T]{}, false, err
+		}
+		b.commit()
+		return Element[T]{}, false, nil
+	}
+	if b.size == b.capacity {
+		var err error
+		if victim, err = b.overflowVictim(element); err != nil {
+			b.counters.rejections++
+			b.logReject("prb: overflow mode rejected insert", element)
+			b.emit(EventReject, element, err)
+			b.bury(element, err)
+			return Element[T]{}, false, err
+		}
+	}
+
+	if !b.quotaAllows(priority, victim) {
+		b.counters.quotaRejections++
+		b.logReject("prb: priority quota rejected insert", element)
+		b.emit(EventReject, element, ErrQuotaExceeded)
+		return Element[T]{}, false, ErrQuotaExceeded
+	}
+
+	var evicted Element[T]
+	if victim >= 0 {
+		evicted = b.elements[victim]
+		b.counters.overwrites++
+		b.logEvict(evicted, element)
+		b.emit(EventEvict, evicted, nil)
+		b.take(victim)
+		b.bury(evicted, ErrEvicted)
+	}
+
+	b.added(element)
+	b.counters.inserts++
+	b.emit(EventInsert, element, nil)
+
+	if b.sorted {
+		b.pushHeap(element)
 	} else {
+		insertIndex := b.tail
+		b.set(insertIndex, element)
+		b.heapAdd(insertIndex)
+		b.tail = b.wrap(b.tail + 1)
 		b.size++
+
+		final, truncated := b.bubbleElement(insertIndex)
+		if truncated {
+			b.unordered = true
+		}
+		b.cacheInserted(final)
+		b.adaptWindow(truncated)
 	}
+	b.counters.highWatermark = max(b.counters.highWatermark, b.size)
 
-	return nil
+	b.commit()
+	return evicted, victim >= 0, nil
 }
 
-func (b *PriorityRingBuffer[T]) bubbleElement(insertIndex int) {
-	for i := 1; i <= b.bubbleWindow && b.size > 0; i++ {
-		previousIndex := (insertIndex - 1 + b.capacity) % b.capacity
+// wrap maps an index in [-capacity, 2*capacity) onto the ring.
+func (b *PriorityRingBuffer[T]) wrap(i int) int {
+	if b.pow2 {
+		return i & (b.capacity - 1)
+	}
+
+	if i < 0 {
+		return i + b.capacity
+	}
 
-		if b.size == b.capacity && previousIndex == b.head {
-			break
+	return i % b.capacity
+}
+
+func (b *PriorityRingBuffer[T]) set(index int, element Element[T]) {
+	b.reindex(index, b.elements[index], element)
+	b.elements[index] = element
+	if b.store != nil {
+		b.store.storeSlot(index, element)
+	}
+	b.heapUpdate(index)
+}
+
+// move relocates the element in slot from to slot to without changing its
+// rank.
+func (b *PriorityRingBuffer[T]) move(from, to int) {
+	b.reindex(to, b.elements[to], b.elements[from])
+	b.elements[to] = b.elements[from]
+	if b.store != nil {
+		b.store.storeSlot(to, b.elements[to])
+	}
+	b.heapMove(from, to)
+	b.cachedMax.CompareAndSwap(int64(from), int64(to))
+}
+
+// commit publishes the ring pointers to the backing store and wakes watchers
+// after a mutation.
+func (b *PriorityRingBuffer[T]) commit() {
+	b.refill()
+	b.version++
+	b.checkInvariants()
+	b.checkWatermarks()
+
+	if b.viewBatch > 0 && b.version%b.viewBatch == 0 {
+		b.publishView()
+	}
+
+	if b.store != nil {
+		b.store.storeHeader(b)
+	}
+
+	b.notify()
+}
+
+// notify wakes every watcher without blocking.
+func (b *PriorityRingBuffer[T]) notify() {
+	for _, w := range b.watchers {
+		select {
+		case w <- struct{}{}:
+		default:
 		}
+	}
+}
+
+func (b *PriorityRingBuffer[T]) watch() chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w := make(chan struct{}, 1)
+	b.watchers = append(b.watchers, w)
+	return w
+}
+
+func (b *PriorityRingBuffer[T]) unwatch(w chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.watchers = slices.DeleteFunc(b.watchers, func(c chan struct{}) bool {
+		return c == w
+	})
+}
+
+// bubbleElement moves the element at insertIndex towards the head by at most
+// bubbleWindow positions. It returns the element's final slot and reports
+// whether the window cut the move short.
+func (b *PriorityRingBuffer[T]) bubbleElement(insertIndex int) (int, bool) {
+	steps := min(b.bubbleWindow, b.size-1)
+	if !b.unordered && steps > 0 {
+		return b.insertSorted(insertIndex, steps)
+	}
+
+	for i := 0; i < steps; i++ {
+		previousIndex := b.wrap(insertIndex - 1)
 
 		current := b.elements[insertIndex]
 		previous := b.elements[previousIndex]
 
-		if b.shouldSwap(current, previous) {
-			b.elements[insertIndex], b.elements[previousIndex] = previous, current
-			insertIndex = previousIndex
-		} else {
-			break
+		if !b.shouldSwap(current, previous) {
+			return insertIndex, false
 		}
+
+		b.set(insertIndex, previous)
+		b.set(previousIndex, current)
+		insertIndex = previousIndex
+	}
+
+	if steps == b.size-1 {
+		return insertIndex, false
+	}
+
+	previousIndex := b.wrap(insertIndex - 1)
+	return insertIndex, b.shouldSwap(b.elements[insertIndex], b.elements[previousIndex])
+}
+
+// insertSorted is bubbleElement for ordered contents: it finds the element's
+// place among the steps elements ahead of it with a binary search and
+// rotates them back by one slot, instead of swapping one step at a time.
+func (b *PriorityRingBuffer[T]) insertSorted(insertIndex, steps int) (int, bool) {
+	element := b.elements[insertIndex]
+	first := b.size - 1 - steps
+	offset := sort.Search(steps, func(j int) bool {
+		return outranks(element, b.elements[b.wrap(b.head+first+j)])
+	})
+	if offset == steps {
+		return insertIndex, false
+	}
+
+	target := b.wrap(b.head + first + offset)
+	b.heapRemove(insertIndex)
+	for j := b.size - 1; j > first+offset; j-- {
+		b.move(b.wrap(b.head+j-1), b.wrap(b.head+j))
+	}
+	b.set(target, element)
+	b.heapAdd(target)
+
+	truncated := offset == 0 && first > 0 && outranks(element, b.elements[b.wrap(b.head+first-1)])
+	return target, truncated
+}
+
+// quotaAllows reports whether an element with priority fits the quotas once
+// the element in slot victim, if not negative, is evicted.
+func (b *PriorityRingBuffer[T]) quotaAllows(priority int, victim int) bool {
+	for _, q := range b.quotas {
+		if !q.covers(priority) {
+			continue
+		}
+
+		used := q.used
+		if victim >= 0 && q.covers(b.elements[victim].Priority) {
+			used--
+		}
+		if used >= q.Limit {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *PriorityRingBuffer[T]) added(e Element[T]) {
+	for i := range b.quotas {
+		if b.quotas[i].covers(e.Priority) {
+			b.quotas[i].used++
+		}
+	}
+}
+
+func (b *PriorityRingBuffer[T]) removed(e Element[T]) {
+	for i := range b.quotas {
+		if b.quotas[i].covers(e.Priority) {
+			b.quotas[i].used--
+		}
+	}
+
+	if e.Key != "" {
+		delete(b.keys, e.Key)
 	}
 }
 
 func (b *PriorityRingBuffer[T]) shouldSwap(current, previous Element[T]) bool {
-	return current.Priority > previous.Priority ||
-		(current.Priority == previous.Priority && current.InsertionOrder < previous.InsertionOrder)
+	return outranks(current, previous)
+}
+
+// outranks reports whether a should be dequeued before b: higher priority
+// first, then older insertion order.
+func outranks[T comparable](a, b Element[T]) bool {
+	return a.Priority > b.Priority ||
+		(a.Priority == b.Priority && a.InsertionOrder < b.InsertionOrder)
+}
+
+func (b *PriorityRingBuffer[T]) Dequeue() (element Element[T], err error) {
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	if b.tracer != nil {
+		start := b.clock.Now()
+		defer func() {
+			b.trace(TraceEvent{
+				Operation: "dequeue",
+				Start:     start,
+				Priority:  element.Priority,
+				Err:       err,
+			})
+		}()
+	}
+
+	if b.size == 0 {
+		b.refill()
+	}
+
+	if b.size == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	return b.dequeue()
+}
+
+// DequeueContext is like Dequeue but waits for an element while the buffer
+// is empty. It returns ctx.Err() if ctx is done first.
+func (b *PriorityRingBuffer[T]) DequeueContext(ctx context.Context) (Element[T], error) {
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	ready := func() bool { return b.size > 0 }
+	if err := b.wait(ready, nil, ctx.Done()); err != nil {
+		return Element[T]{}, err
+	}
+	if b.size == 0 {
+		return Element[T]{}, ctx.Err()
+	}
+
+	return b.dequeue()
+}
+
+// TryDequeue is Dequeue reporting failure, usually an empty buffer, as
+// false instead of an error.
+func (b *PriorityRingBuffer[T]) TryDequeue() (Element[T], bool) {
+	element, err := b.Dequeue()
+	return element, err == nil
+}
+
+// DequeueTimeout is like Dequeue but waits up to d for an element while the
+// buffer is empty before giving up with ErrBufferEmpty.
+func (b *PriorityRingBuffer[T]) DequeueTimeout(d time.Duration) (Element[T], error) {
+	timer := b.clock.NewTimer(d)
+	defer timer.Stop()
+
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	ready := func() bool { return b.size > 0 }
+	if err := b.wait(ready, timer.C(), nil); err != nil {
+		return Element[T]{}, err
+	}
+	if b.size == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	return b.dequeue()
+}
+
+// dequeue removes and returns the head element. The buffer must not be
+// empty and the caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) dequeue() (Element[T], error) {
+	if b.latency != nil {
+		defer b.latency.dequeue.since(b.clock, b.clock.Now())
+	}
+
+	if b.decay != nil {
+		return b.dequeueDecayed()
+	}
+
+	if err := b.logOp(walDequeue); err != nil {
+		return Element[T]{}, err
+	}
+
+	return b.pop(), nil
 }
 
-func (b *PriorityRingBuffer[T]) Dequeue() (Element[T], error) {
+// DequeueInto dequeues up to len(dst) elements into dst under a single lock
+// acquisition and returns how many were written. With decay on it picks
+// them the way Dequeue does.
+func (b *PriorityRingBuffer[T]) DequeueInto(dst []Element[T]) (n int, err error) {
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, ErrClosed
+	}
+
+	if b.tracer != nil {
+		start := b.clock.Now()
+		defer func() {
+			var priority int
+			if n > 0 {
+				priority = dst[0].Priority
+			}
+			b.trace(TraceEvent{
+				Operation: "dequeueInto",
+				Start:     start,
+				Priority:  priority,
+				Err:       err,
+			})
+		}()
+	}
+
+	if b.latency != nil {
+		defer b.latency.dequeue.since(b.clock, b.clock.Now())
+	}
+
+	n = min(len(dst), b.size)
+	if n == 0 {
+		return 0, nil
+	}
+
+	// Decayed dequeues are logged one by one, by position.
+	if b.decay != nil {
+		for i := range n {
+			element, err := b.dequeueDecayed()
+			if err != nil {
+				return i, err
+			}
+			dst[i] = element
+		}
+		return n, nil
+	}
+
+	if err := b.logDequeueBatch(n); err != nil {
+		return 0, err
+	}
+
+	for i := range n {
+		dst[i] = b.pop()
+	}
+
+	return n, nil
+}
+
+func (b *PriorityRingBuffer[T]) pop() Element[T] {
+	guaranteed := !b.unordered || b.size == 1
+	element := b.take(b.head)
+	element.GuaranteedMax = guaranteed
+	b.counters.dequeues++
+	b.emit(EventDequeue, element, nil)
+
+	b.commit()
+	return element
+}
+
+// ReplaceHead swaps the head element for value at priority, keeping the
+// head's insertion order and therefore its queue position. If the new
+// priority ranks below elements behind it, the element sinks towards the
+// tail by at most bubbleWindow positions.
+func (b *PriorityRingBuffer[T]) ReplaceHead(value T, priority int) (old Element[T], err error) {
+	b.lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	if b.tracer != nil {
+		start := b.clock.Now()
+		defer func() {
+			b.trace(TraceEvent{
+				Operation: "replaceHead",
+				Start:     start,
+				Priority:  old.Priority,
+				Err:       err,
+			})
+		}()
+	}
+
+	if b.latency != nil {
+		defer b.latency.dequeue.since(b.clock, b.clock.Now())
+	}
+
+	if b.size == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	if !b.quotaAllows(priority, b.head) {
+		return Element[T]{}, ErrQuotaExceeded
+	}
+
+	if err := b.logReplaceHead(value, priority); err != nil {
+		return Element[T]{}, err
+	}
+
+	old = b.elements[b.head]
+	element := Element[T]{
+		Value:          value,
+		Priority:       priority,
+		InsertionOrder: old.InsertionOrder,
+	}
+	b.stamp(&element)
+
+	b.removed(old)
+	b.emit(EventRemove, old, nil)
+	b.cachedMax.CompareAndSwap(int64(b.head), -1)
+	b.set(b.head, element)
+	b.added(element)
+	b.emit(EventInsert, element, nil)
+
+	if b.sorted {
+		b.siftDown(b.head)
+	} else {
+		final, truncated := b.sinkElement(b.head)
+		if truncated {
+			b.unordered = true
+		}
+		b.cacheInserted(final)
+	}
+
+	b.commit()
+	return old, nil
+}
+
+// sinkElement is the mirror of bubbleElement, moving the element at index
+// towards the tail while the element behind it outranks it.
+func (b *PriorityRingBuffer[T]) sinkElement(index int) (int, bool) {
+	behind := b.wrap(b.tail - 1 - index)
+	steps := min(b.bubbleWindow, behind)
+	for i := 0; i < steps; i++ {
+		nextIndex := b.wrap(index + 1)
+
+		current := b.elements[index]
+		next := b.elements[nextIndex]
+
+		if !b.shouldSwap(next, current) {
+			return index, false
+		}
+
+		b.set(index, next)
+		b.set(nextIndex, current)
+		b.cachedMax.CompareAndSwap(int64(nextIndex), int64(index))
+		index = nextIndex
+	}
+
+	if steps == behind {
+		return index, false
+	}
+
+	return index, b.shouldSwap(b.elements[b.wrap(index+1)], b.elements[index])
+}
+
+// remove takes the element in slot out of order and records it as a
+// removal.
+func (b *PriorityRingBuffer[T]) remove(slot int) Element[T] {
+	element := b.take(slot)
+	b.counters.removals++
+	b.emit(EventRemove, element, nil)
+
+	b.commit()
+	return element
+}
+
+// RemoveAt removes the element at logical position i, as returned by
+// Search, and closes the gap.
+func (b *PriorityRingBuffer[T]) RemoveAt(i int) (Element[T], error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
+	if i < 0 || i >= b.size {
+		return Element[T]{}, ErrIndexOutOfRange
+	}
+
+	if err := b.logRemoveAt(i); err != nil {
+		return Element[T]{}, err
+	}
+
+	return b.remove(b.slot(i)), nil
+}
+
+// RemoveMin removes and returns the lowest priority element, the oldest one
+// among equal priorities.
+func (b *PriorityRingBuffer[T]) RemoveMin() (Element[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Element[T]{}, ErrClosed
+	}
+
 	if b.size == 0 {
 		return Element[T]{}, ErrBufferEmpty
 	}
 
-	element := b.elements[b.head]
-	b.head = (b.head + 1) % b.capacity
+	if err := b.logOp(walRemoveMin); err != nil {
+		return Element[T]{}, err
+	}
+
+	return b.remove(b.minSlot()), nil
+}
+
+// take removes the element in slot index, shifting whichever side of the
+// ring is shorter to close the gap. Relative order is preserved.
+func (b *PriorityRingBuffer[T]) take(index int) Element[T] {
+	if b.sorted {
+		return b.takeHeap(index)
+	}
+
+	i := b.logical(index)
+	element := b.elements[index]
+	b.heapRemove(index)
+	b.cachedMax.CompareAndSwap(int64(index), -1)
+
+	if i < b.size/2 {
+		for j := i; j > 0; j-- {
+			b.move(b.wrap(b.head+j-1), b.wrap(b.head+j))
+		}
+		b.head = b.wrap(b.head + 1)
+	} else {
+		for j := i; j < b.size-1; j++ {
+			b.move(b.wrap(b.head+j+1), b.wrap(b.head+j))
+		}
+		b.tail = b.wrap(b.tail - 1)
+	}
+
 	b.size--
+	b.removed(element)
+
+	if b.size == 0 {
+		b.unordered = false
+	}
 
-	return element, nil
+	return element
 }
 
 func (b *PriorityRingBuffer[T]) Peek() (Element[T], error) {
@@ -147,7 +1028,26 @@ func (b *PriorityRingBuffer[T]) Peek() (Element[T], error) {
 		return Element[T]{}, ErrBufferEmpty
 	}
 
-	return b.elements[b.head], nil
+	return b.front(), nil
+}
+
+// TryPeek is Peek reporting an empty buffer as false instead of an error.
+func (b *PriorityRingBuffer[T]) TryPeek() (Element[T], bool) {
+	element, err := b.Peek()
+	return element, err == nil
+}
+
+// front returns the element Dequeue would remove next, with its decayed
+// priority if decay is on. The buffer must not be empty.
+func (b *PriorityRingBuffer[T]) front() Element[T] {
+	if b.decay != nil {
+		slot, priority := b.decayedSlot()
+		element := b.elements[slot]
+		element.Priority = priority
+		return element
+	}
+
+	return b.elements[b.head]
 }
 
 func (b *PriorityRingBuffer[T]) PeekMaxPriority() (Element[T], error) {
@@ -158,15 +1058,36 @@ func (b *PriorityRingBuffer[T]) PeekMaxPriority() (Element[T], error) {
 		return Element[T]{}, ErrBufferEmpty
 	}
 
-	maxIndex := b.head
+	return b.elements[b.maxSlot()], nil
+}
+
+// MaxPriority returns the highest priority currently queued.
+func (b *PriorityRingBuffer[T]) MaxPriority() (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.size == 0 {
+		return 0, ErrBufferEmpty
+	}
+
+	return b.elements[b.maxSlot()].Priority, nil
+}
+
+// MinPriority returns the lowest priority currently queued.
+func (b *PriorityRingBuffer[T]) MinPriority() (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.size == 0 {
+		return 0, ErrBufferEmpty
+	}
+
+	minPriority := b.elements[b.head].Priority
 	for i := 1; i < b.size; i++ {
-		index := (b.head + i) % b.capacity
-		if b.shouldSwap(b.elements[index], b.elements[maxIndex]) {
-			maxIndex = index
-		}
+		minPriority = min(minPriority, b.elements[b.wrap(b.head+i)].Priority)
 	}
 
-	return b.elements[maxIndex], nil
+	return minPriority, nil
 }
 
 type SearchFilter[T comparable] func(Element[T]) bool
@@ -175,25 +1096,86 @@ func (b *PriorityRingBuffer[T]) Search(filters ...SearchFilter[T]) []int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
+	if b.latency != nil {
+		defer b.latency.search.since(b.clock, b.clock.Now())
+	}
+
 	var result []int
+	b.each(func(i, slot int) bool {
+		if matches(b.elements[slot], filters) {
+			result = append(result, i)
+		}
+		return true
+	})
+
+	return result
+}
+
+// Count returns the number of elements matching all filters.
+func (b *PriorityRingBuffer[T]) Count(filters ...SearchFilter[T]) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	count := 0
 	for i := 0; i < b.size; i++ {
-		index := (b.head + i) % b.capacity
-		element := b.elements[index]
-
-		match := true
-		for _, filter := range filters {
-			if !filter(element) {
-				match = false
-				break
+		if matches(b.elements[b.wrap(b.head+i)], filters) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// SearchFunc calls fn with the logical index and a copy of every element
+// matching all filters, in dequeue order, until fn returns false. fn runs
+// under the read lock and must not call back into the buffer.
+func (b *PriorityRingBuffer[T]) SearchFunc(fn func(i int, e Element[T]) bool, filters ...SearchFilter[T]) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	b.each(func(i, slot int) bool {
+		element := b.elements[slot]
+		return !matches(element, filters) || fn(i, element)
+	})
+}
+
+// ForEach calls fn for every element in dequeue order until fn returns
+// false. fn runs under the read lock and must not call back into the buffer.
+func (b *PriorityRingBuffer[T]) ForEach(fn func(i int, e Element[T]) bool) {
+	b.SearchFunc(fn)
+}
+
+// ForEachReverse is ForEach walking from tail to head, newest insertions
+// first; i is still the position counted from head.
+func (b *PriorityRingBuffer[T]) ForEachReverse(fn func(i int, e Element[T]) bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.sorted {
+		slots := b.sortedSlots()
+		for i := b.size - 1; i >= 0; i-- {
+			if !fn(i, b.elements[slots[i]]) {
+				return
 			}
 		}
+		return
+	}
 
-		if match {
-			result = append(result, i)
+	for i := b.size - 1; i >= 0; i-- {
+		if !fn(i, b.elements[b.wrap(b.head+i)]) {
+			return
 		}
 	}
+}
 
-	return result
+func matches[T comparable](e Element[T], filters []SearchFilter[T]) bool {
+	for _, filter := range filters {
+		if !filter(e) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func SearchByValue[T comparable](value T) SearchFilter[T] {
@@ -244,39 +1226,216 @@ func (b *PriorityRingBuffer[T]) Snapshot() []Element[T] {
 		return nil
 	}
 
-	result := make([]Element[T], b.size)
+	return b.state().Elements
+}
+
+// SortedSnapshot returns a copy of the contents fully sorted by priority,
+// then insertion order, regardless of bubbleWindow.
+func (b *PriorityRingBuffer[T]) SortedSnapshot() []Element[T] {
+	result := b.Snapshot()
+	slices.SortFunc(result, func(x, y Element[T]) int {
+		switch {
+		case outranks(x, y):
+			return -1
+		case outranks(y, x):
+			return 1
+		}
+		return 0
+	})
+
+	return result
+}
+
+// PriorityHistogram returns the number of queued elements per priority.
+func (b *PriorityRingBuffer[T]) PriorityHistogram() map[int]int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	histogram := make(map[int]int)
 	for i := 0; i < b.size; i++ {
-		index := (b.head + i) % b.capacity
-		result[i] = b.elements[index]
+		histogram[b.elements[b.wrap(b.head+i)].Priority]++
 	}
 
-	return result
+	return histogram
 }
 
+// Clear removes every element, including spilled ones. Use ClearErr to learn
+// whether logging the clear or discarding the spill file failed.
 func (b *PriorityRingBuffer[T]) Clear() {
+	_ = b.ClearErr()
+}
+
+// ClearErr is like Clear but returns an error if the buffer is closed, the
+// clear could not be logged, in which case nothing was removed, or the spill
+// file could not be truncated.
+func (b *PriorityRingBuffer[T]) ClearErr() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.closed {
+		return ErrClosed
+	}
+
+	if err := b.logOp(walClear); err != nil {
+		return err
+	}
+
 	b.head = 0
 	b.tail = 0
 	b.size = 0
+	b.unordered = false
+	b.keys = nil
+	b.heapReset()
+	b.cachedMax.Store(-1)
+
+	for i := range b.quotas {
+		b.quotas[i].used = 0
+	}
+
+	var err error
+	if b.spill != nil {
+		err = b.spill.reset()
+	}
+
+	b.emit(EventClear, Element[T]{}, nil)
+	b.commit()
+	return err
 }
 
 type Stats struct {
-	Size         int
-	Capacity     int
-	BubbleWindow int
-	OrderCounter int64
+	Size            int
+	Capacity        int
+	BubbleWindow    int
+	OrderCounter    int64
+	Inserts         uint64
+	Dequeues        uint64
+	Overwrites      uint64
+	GuardRejections uint64
+	QuotaRejections uint64
+	RateRejections  uint64
+	Removals        uint64
+	DroppedEvents   uint64
+	HighWatermark   int
+	// Spilled is the number of elements waiting in the spill file.
+	Spilled int
+	// Latency is nil unless the buffer was created with
+	// WithLatencyHistograms.
+	Latency *LatencyStats `json:",omitempty"`
+}
+
+type counters struct {
+	inserts         uint64
+	dequeues        uint64
+	overwrites      uint64
+	rejections      uint64
+	quotaRejections uint64
+	rateRejections  uint64
+	removals        uint64
+	droppedEvents   uint64
+	highWatermark   int
 }
 
 func (b *PriorityRingBuffer[T]) GetStats() Stats {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
+	var latency *LatencyStats
+	if b.latency != nil {
+		latency = b.latency.stats()
+	}
+
+	spilled := 0
+	if b.spill != nil {
+		spilled = b.spill.count
+	}
+
 	return Stats{
-		Size:         b.size,
-		Capacity:     b.capacity,
-		BubbleWindow: b.bubbleWindow,
-		OrderCounter: b.orderCounter,
+		Size:            b.size,
+		Capacity:        b.capacity,
+		BubbleWindow:    b.bubbleWindow,
+		OrderCounter:    b.orderCounter,
+		Inserts:         b.counters.inserts,
+		Dequeues:        b.counters.dequeues,
+		Overwrites:      b.counters.overwrites,
+		GuardRejections: b.counters.rejections,
+		QuotaRejections: b.counters.quotaRejections,
+		RateRejections:  b.counters.rateRejections,
+		Removals:        b.counters.removals,
+		DroppedEvents:   b.counters.droppedEvents,
+		HighWatermark:   b.counters.highWatermark,
+		Spilled:         spilled,
+		Latency:         latency,
 	}
 }
+
+// ResetStats zeroes the operation counters and latency histograms and
+// restarts the high watermark from the current size.
+func (b *PriorityRingBuffer[T]) ResetStats() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.counters = counters{highWatermark: b.size}
+	if b.latency != nil {
+		b.latency = &latencies{}
+	}
+}
+
+// SetBubbleWindow changes how far future inserts may move towards the head.
+// Queued elements keep their positions, unless the window grows to
+// capacity-1 and the buffer switches to its fully ordered layout.
+func (b *PriorityRingBuffer[T]) SetBubbleWindow(window int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if window < 0 || window > b.capacity-1 {
+		return ErrInvalidWindow
+	}
+
+	if err := b.logSetBubbleWindow(window); err != nil {
+		return err
+	}
+
+	b.bubbleWindow = window
+	b.setLayout()
+	b.commit()
+	return nil
+}
+
+type OrderingGuarantee struct {
+	MaxDisplacement int
+	FullyOrdered    bool
+}
+
+// OrderingGuarantee reports how far an element may sit behind its true
+// priority position: at most capacity-1-bubbleWindow lower priority elements
+// can be queued ahead of it.
+func (b *PriorityRingBuffer[T]) OrderingGuarantee() OrderingGuarantee {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	displacement := max(b.capacity-1-b.bubbleWindow, 0)
+	return OrderingGuarantee{
+		MaxDisplacement: displacement,
+		FullyOrdered:    displacement == 0,
+	}
+}
+
+func (b *PriorityRingBuffer[T]) Quotas() []Quota {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.quotas) == 0 {
+		return nil
+	}
+
+	result := make([]Quota, len(b.quotas))
+	for i, q := range b.quotas {
+		result[i] = q.Quota
+	}
+
+	return result
+}
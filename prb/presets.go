@@ -0,0 +1,48 @@
+package prb
+
+const (
+	// defaultWindow keeps inserts cheap while ordering bursts of mixed
+	// priorities.
+	defaultWindow = 16
+	// telemetryWindow only smooths out neighbouring samples.
+	telemetryWindow = 4
+)
+
+// NewDefault builds a buffer suited to most queues: a bubble window of 16,
+// or capacity-1 for smaller buffers, which then stay fully ordered, and the
+// Reject overflow mode, so a full buffer only makes room for higher priority
+// work. opts are applied afterwards and override the preset.
+func NewDefault[T comparable](capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
+	preset := []Option[T]{
+		WithBubbleWindow[T](min(defaultWindow, max(capacity-1, 0))),
+		WithOverflowMode[T](Reject),
+	}
+
+	return New(capacity, append(preset, opts...)...)
+}
+
+// NewStrictPriority builds a buffer that always dequeues in exact priority
+// order, using the heap layout, and that sheds the lowest priority element
+// when full. opts are applied afterwards and override the preset.
+func NewStrictPriority[T comparable](capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
+	preset := []Option[T]{
+		WithBubbleWindow[T](max(capacity-1, 0)),
+		WithOverflowMode[T](DropLowestPriority),
+	}
+
+	return New(capacity, append(preset, opts...)...)
+}
+
+// NewLossyTelemetry builds a buffer for samples where freshness matters more
+// than completeness: inserts never fail or block, the oldest element is
+// dropped when full, and a small bubble window keeps inserts constant time
+// at the cost of only roughly ordering priorities. opts are applied
+// afterwards and override the preset.
+func NewLossyTelemetry[T comparable](capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
+	preset := []Option[T]{
+		WithBubbleWindow[T](min(telemetryWindow, max(capacity-1, 0))),
+		WithOverflowMode[T](DropOldest),
+	}
+
+	return New(capacity, append(preset, opts...)...)
+}
@@ -0,0 +1,47 @@
+package prb
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRateLimited      = errors.New("insert rate limit exceeded")
+	ErrInvalidRateLimit = errors.New("rate limit needs a positive rate and a burst of at least one")
+)
+
+// WithRateLimit throttles Insert to perSecond elements per second on
+// average, allowing bursts of up to burst. Inserts over the limit fail with
+// ErrRateLimited and are counted apart from capacity rejections; only
+// accepted inserts use up the allowance.
+func WithRateLimit[T comparable](perSecond float64, burst int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.limiter = &limiter{rate: perSecond, burst: float64(burst), tokens: float64(burst)}
+	}
+}
+
+// limiter is a token bucket refilled lazily from the buffer clock.
+type limiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (l *limiter) valid() bool {
+	return l.rate > 0 && l.burst >= 1
+}
+
+// allow refills the bucket up to now and reports whether a token is left.
+func (l *limiter) allow(now time.Time) bool {
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	return l.tokens >= 1
+}
+
+func (l *limiter) take() {
+	l.tokens--
+}
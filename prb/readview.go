@@ -0,0 +1,128 @@
+package prb
+
+import "iter"
+
+// ReadView is an immutable copy of a buffer's contents that can be read
+// without taking the buffer lock.
+type ReadView[T comparable] struct {
+	elements []Element[T]
+	capacity int
+	version  uint64
+}
+
+// WithReadView publishes a fresh ReadView after every batch mutations, so
+// LoadView never lags more than batch mutations behind the buffer.
+func WithReadView[T comparable](batch int) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.viewBatch = uint64(max(batch, 0))
+	}
+}
+
+// publishView copies the live elements into a new ReadView. The caller must
+// hold the lock.
+func (b *PriorityRingBuffer[T]) publishView() {
+	b.view.Store(&ReadView[T]{
+		elements: b.state().Elements,
+		capacity: b.capacity,
+		version:  b.version,
+	})
+}
+
+// LoadView returns the most recently published ReadView without blocking,
+// or nil if the buffer was not created WithReadView and RefreshView was never
+// called.
+func (b *PriorityRingBuffer[T]) LoadView() *ReadView[T] {
+	return b.view.Load()
+}
+
+// RefreshView publishes a ReadView of the current state and returns it.
+func (b *PriorityRingBuffer[T]) RefreshView() *ReadView[T] {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	b.publishView()
+	return b.view.Load()
+}
+
+// SnapshotIter captures the buffer's elements and returns an iterator over
+// them in dequeue order with their logical indexes. Iteration happens
+// outside the lock and is unaffected by later writes. If the published
+// ReadView is current it is shared instead of copying the elements.
+func (b *PriorityRingBuffer[T]) SnapshotIter() iter.Seq2[int, Element[T]] {
+	b.mu.RLock()
+	var elements []Element[T]
+	if v := b.view.Load(); v != nil && v.version == b.version {
+		elements = v.elements
+	} else {
+		elements = b.state().Elements
+	}
+	b.mu.RUnlock()
+
+	return func(yield func(int, Element[T]) bool) {
+		for i, e := range elements {
+			if !yield(i, e) {
+				return
+			}
+		}
+	}
+}
+
+// Version is the buffer's mutation count when the view was taken.
+func (v *ReadView[T]) Version() uint64 {
+	return v.version
+}
+
+func (v *ReadView[T]) Len() int {
+	return len(v.elements)
+}
+
+func (v *ReadView[T]) Cap() int {
+	return v.capacity
+}
+
+func (v *ReadView[T]) IsEmpty() bool {
+	return len(v.elements) == 0
+}
+
+func (v *ReadView[T]) Peek() (Element[T], error) {
+	if len(v.elements) == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	return v.elements[0], nil
+}
+
+func (v *ReadView[T]) PeekMaxPriority() (Element[T], error) {
+	if len(v.elements) == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	best := v.elements[0]
+	for _, e := range v.elements[1:] {
+		if outranks(e, best) {
+			best = e
+		}
+	}
+
+	return best, nil
+}
+
+func (v *ReadView[T]) Search(filters ...SearchFilter[T]) []int {
+	var result []int
+	for i, e := range v.elements {
+		if matches(e, filters) {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+// Snapshot returns a copy of the view's elements in dequeue order.
+func (v *ReadView[T]) Snapshot() []Element[T] {
+	if len(v.elements) == 0 {
+		return nil
+	}
+
+	return append([]Element[T](nil), v.elements...)
+}
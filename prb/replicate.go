@@ -0,0 +1,156 @@
+package prb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+)
+
+var ErrSelfFollow = errors.New("a buffer cannot follow itself")
+
+// Replicator mirrors every mutation of a leader buffer to followers, so a
+// standby holds the same queued work and can take over. Followers are sent
+// the leader's write-ahead log records, whether or not the leader keeps a
+// log, and must not be mutated directly while they follow. They should not
+// rate limit or adapt their bubble window, as replaying does neither.
+type Replicator[T comparable] struct {
+	leader   *PriorityRingBuffer[T]
+	replicas []*replica
+}
+
+type replica struct {
+	send func(record []byte) error
+	err  error
+}
+
+func NewReplicator[T comparable](leader *PriorityRingBuffer[T]) *Replicator[T] {
+	return &Replicator[T]{leader: leader}
+}
+
+// Follow restores f to the leader's current state and then applies every
+// mutation of the leader to f as it happens.
+func (r *Replicator[T]) Follow(f *PriorityRingBuffer[T]) error {
+	if f == r.leader {
+		return ErrSelfFollow
+	}
+	if f.secondary != nil {
+		return ErrChainedLog
+	}
+
+	return r.attach(func(record []byte) error {
+		frame := binary.AppendUvarint(nil, uint64(len(record)))
+		_, err := f.replay(append(frame, record...), false, nil)
+		return err
+	}, func() error {
+		return f.Restore(r.leader.state())
+	})
+}
+
+// Stream writes the leader's current state and then every mutation to w,
+// framed like a write-ahead log and compressed and encrypted like the
+// leader's, until a write fails. Apply the stream to a follower with
+// FollowStream. Writes happen under the leader's lock, so w
+// should not block for long.
+func (r *Replicator[T]) Stream(w io.Writer) error {
+	send := func(record []byte) error {
+		frame := binary.AppendUvarint(nil, uint64(len(record)))
+		_, err := w.Write(append(frame, record...))
+		return err
+	}
+
+	return r.attach(send, func() error {
+		b := r.leader
+		record, err := appendState([]byte{walState}, b.state(), b.valueCodec())
+		if err == nil {
+			// The seed is not part of the leader's log.
+			record, err = b.sealRecord(record, &sequence{})
+		}
+		if err != nil {
+			return err
+		}
+		return send(record)
+	})
+}
+
+// attach seeds a new replica under the leader's lock and registers it.
+func (r *Replicator[T]) attach(send func([]byte) error, seed func() error) error {
+	b := r.leader
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if b.secondary != nil {
+		return ErrChainedLog
+	}
+
+	if err := seed(); err != nil {
+		return err
+	}
+
+	rep := &replica{send: send}
+	r.replicas = append(r.replicas, rep)
+	b.replicas = append(b.replicas, rep)
+	return nil
+}
+
+// Err returns the error that stopped the first failed follower or stream,
+// if any. Failed replicas receive no further mutations.
+func (r *Replicator[T]) Err() error {
+	r.leader.mu.RLock()
+	defer r.leader.mu.RUnlock()
+
+	for _, rep := range r.replicas {
+		if rep.err != nil {
+			return rep.err
+		}
+	}
+
+	return nil
+}
+
+// Stop detaches every follower and stream, leaving the followers ready to
+// be used, for example after a failover.
+func (r *Replicator[T]) Stop() {
+	b := r.leader
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replicas = slices.DeleteFunc(b.replicas, func(rep *replica) bool {
+		return slices.Contains(r.replicas, rep)
+	})
+	r.replicas = nil
+}
+
+// FollowStream applies a stream written by Replicator.Stream to f until r
+// ends. It returns nil at the end of the stream.
+func FollowStream[T comparable](r io.Reader, f *PriorityRingBuffer[T]) error {
+	if f.secondary != nil {
+		return ErrChainedLog
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		length, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		frame := binary.AppendUvarint(nil, length)
+		frame = append(frame, make([]byte, length)...)
+		if _, err := io.ReadFull(br, frame[len(frame)-int(length):]); err != nil {
+			return err
+		}
+
+		if _, err := f.replay(frame, false, nil); err != nil {
+			return err
+		}
+	}
+}
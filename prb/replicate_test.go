@@ -0,0 +1,99 @@
+package prb_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestReplication(t *testing.T) {
+	large := strings.Repeat("x", 1000)
+
+	tests := []struct {
+		name string
+		opts []prb.Option[string]
+		wal  bool
+	}{
+		{"plain", nil, false},
+		{"compressed", []prb.Option[string]{prb.WithCompression[string](prb.GzipCompressor{})}, false},
+		{"encrypted", []prb.Option[string]{prb.WithEncryptionKey[string](key0)}, false},
+		{"encrypted with log", []prb.Option[string]{prb.WithEncryptionKey[string](key0)}, true},
+		{"compressed and encrypted", []prb.Option[string]{
+			prb.WithCompression[string](prb.GzipCompressor{}),
+			prb.WithEncryptionKey[string](key0),
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leader := prb.MustNew[string](8, tt.opts...)
+			if tt.wal {
+				var err error
+				leader, err = prb.OpenFromWAL[string](filepath.Join(t.TempDir(), "wal"), 8, tt.opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer leader.Close()
+			}
+			for i, v := range []string{"seed", large} {
+				if err := leader.Insert(v, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := prb.NewReplicator(leader)
+			follower := prb.MustNew[string](8, tt.opts...)
+			if err := r.Follow(follower); err != nil {
+				t.Fatalf("Follow: %v", err)
+			}
+			var stream bytes.Buffer
+			if err := r.Stream(&stream); err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+
+			if err := leader.Insert("next", 5); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := leader.Dequeue(); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Err(); err != nil {
+				t.Fatalf("Err: %v", err)
+			}
+
+			if tt.opts != nil && bytes.Contains(stream.Bytes(), []byte(large)) {
+				t.Fatal("stream contains the uncompressed, unencrypted seed")
+			}
+
+			streamed := prb.MustNew[string](8, tt.opts...)
+			if err := prb.FollowStream(bytes.NewReader(stream.Bytes()), streamed); err != nil {
+				t.Fatalf("FollowStream: %v", err)
+			}
+			for _, f := range []*prb.PriorityRingBuffer[string]{follower, streamed} {
+				if !slices.Equal(contents(f), contents(leader)) {
+					t.Fatalf("follower holds %v, want %v", contents(f), contents(leader))
+				}
+			}
+		})
+	}
+}
+
+func TestStreamNeedsKeys(t *testing.T) {
+	leader := prb.MustNew[string](4, prb.WithEncryptionKey[string](key0))
+	if err := leader.Insert("a", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var stream bytes.Buffer
+	if err := prb.NewReplicator(leader).Stream(&stream); err != nil {
+		t.Fatal(err)
+	}
+	if err := prb.FollowStream(&stream, prb.MustNew[string](4)); !errors.Is(err, prb.ErrEncrypted) {
+		t.Fatalf("FollowStream: %v, want ErrEncrypted", err)
+	}
+}
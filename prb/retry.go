@@ -0,0 +1,97 @@
+package prb
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes how long a failed delivery waits before it is queued
+// again. The delay for attempt n is Base doubled n-1 times, capped at Max,
+// then reduced by up to Jitter (a fraction between 0 and 1) at random.
+type Backoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter float64
+	// MaxAttempts sends an element to the dead letter instead of retrying
+	// it once it has been delivered this many times. Zero retries forever.
+	MaxAttempts int
+}
+
+// Delay returns the delay before retrying after the given attempt.
+func (p Backoff) Delay(attempt int) time.Duration {
+	delay := p.Base
+	for i := 1; i < attempt && (p.Max <= 0 || delay < p.Max); i++ {
+		delay *= 2
+	}
+	if p.Max > 0 {
+		delay = min(delay, p.Max)
+	}
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * min(p.Jitter, 1) * rand.Float64())
+	}
+
+	return delay
+}
+
+// Retry consumes deliveries from a buffer and requeues failed ones with
+// backoff. An element stays in flight while it waits, so it is neither
+// visible to other consumers nor subject to the ack timeout.
+type Retry[T comparable] struct {
+	buffer  *PriorityRingBuffer[T]
+	backoff Backoff
+}
+
+func NewRetry[T comparable](b *PriorityRingBuffer[T], backoff Backoff) *Retry[T] {
+	return &Retry[T]{buffer: b, backoff: backoff}
+}
+
+// Handle dequeues a delivery and passes its element to fn. The delivery is
+// acknowledged if fn returns nil and failed otherwise; fn's error is
+// returned.
+func (r *Retry[T]) Handle(fn func(Element[T]) error) error {
+	d, err := r.buffer.DequeueDelivery()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(d.Element); err != nil {
+		r.Fail(d)
+		return err
+	}
+
+	return r.buffer.Ack(d.ID)
+}
+
+// Fail requeues the element of d at its original priority after the backoff
+// delay for its attempt, or hands it to the dead letter with reason
+// ErrMaxAttempts once it has used up its attempts.
+func (r *Retry[T]) Fail(d Delivery[T]) error {
+	b := r.buffer
+	if r.backoff.MaxAttempts > 0 && d.Attempts >= r.backoff.MaxAttempts {
+		b.mu.Lock()
+		f, err := b.settle(d.ID)
+		b.mu.Unlock()
+
+		if err == nil && b.deadLetter != nil {
+			b.deadLetter(f.element, ErrMaxAttempts)
+		}
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, ok := b.deliveries[d.ID]
+	if !ok {
+		return ErrUnknownDelivery
+	}
+
+	if f.stop != nil {
+		close(f.stop)
+	}
+	f.stop = make(chan struct{})
+	b.nackAfter(d.ID, r.backoff.Delay(d.Attempts), f.stop)
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package prb
+
+import (
+	"errors"
+	"hash/maphash"
+	"sync/atomic"
+)
+
+var ErrInvalidShards = errors.New("shard count must be positive")
+
+// ShardedPRB spreads inserts over independent rings so producers rarely
+// contend. Dequeue serves the best head across shards, so ordering is only
+// approximate across shards even with full bubble windows.
+type ShardedPRB[T comparable] struct {
+	shards       []*PriorityRingBuffer[T]
+	shardOpts    []Option[T]
+	hashed       bool
+	seed         maphash.Seed
+	next         atomic.Uint64
+	orderCounter atomic.Int64
+}
+
+type ShardedOption[T comparable] func(*ShardedPRB[T])
+
+// WithShardOptions applies opts to every shard. The shards must not use
+// Block.
+func WithShardOptions[T comparable](opts ...Option[T]) ShardedOption[T] {
+	return func(s *ShardedPRB[T]) {
+		s.shardOpts = append(s.shardOpts, opts...)
+	}
+}
+
+// WithHashSharding places elements by a hash of their value instead of
+// round-robin, keeping equal values on the same shard.
+func WithHashSharding[T comparable]() ShardedOption[T] {
+	return func(s *ShardedPRB[T]) {
+		s.hashed = true
+	}
+}
+
+func NewSharded[T comparable](shards, capacityPerShard int, opts ...ShardedOption[T]) (*ShardedPRB[T], error) {
+	if shards <= 0 {
+		return nil, ErrInvalidShards
+	}
+
+	s := &ShardedPRB[T]{
+		shards: make([]*PriorityRingBuffer[T], shards),
+		seed:   maphash.MakeSeed(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for i := range s.shards {
+		shard, err := New(capacityPerShard, s.shardOpts...)
+		if err != nil {
+			return nil, err
+		}
+		// Inserts go to one shard, so a blocked insert would wait for room
+		// in that shard even while the others have plenty.
+		if shard.OverflowMode() == Block {
+			return nil, ErrInvalidOverflowMode
+		}
+		s.shards[i] = shard
+	}
+
+	return s, nil
+}
+
+func (s *ShardedPRB[T]) shardFor(value T) *PriorityRingBuffer[T] {
+	if s.hashed {
+		return s.shards[maphash.Comparable(s.seed, value)%uint64(len(s.shards))]
+	}
+
+	return s.shards[(s.next.Add(1)-1)%uint64(len(s.shards))]
+}
+
+func (s *ShardedPRB[T]) Insert(value T, priority int) error {
+	_, _, err := s.shardFor(value).insert(Element[T]{
+		Value:          value,
+		Priority:       priority,
+		InsertionOrder: s.orderCounter.Add(1) - 1,
+		keepOrder:      true,
+	}, nil, nil)
+	return err
+}
+
+// best returns the shard whose head should be served next and that head.
+func (s *ShardedPRB[T]) best() (*PriorityRingBuffer[T], Element[T], bool) {
+	var best *PriorityRingBuffer[T]
+	var head Element[T]
+
+	for _, shard := range s.shards {
+		candidate, err := shard.Peek()
+		if err != nil {
+			continue
+		}
+
+		if best == nil || outranks(candidate, head) {
+			best, head = shard, candidate
+		}
+	}
+
+	return best, head, best != nil
+}
+
+func (s *ShardedPRB[T]) Dequeue() (Element[T], error) {
+	for {
+		shard, head, ok := s.best()
+		if !ok {
+			return Element[T]{}, ErrBufferEmpty
+		}
+
+		// Another consumer, or decay, may have changed the shard's front
+		// since it was peeked; try again if so.
+		shard.mu.Lock()
+		if shard.size > 0 && shard.front().InsertionOrder == head.InsertionOrder {
+			element, err := shard.dequeue()
+			shard.mu.Unlock()
+			return element, err
+		}
+		shard.mu.Unlock()
+	}
+}
+
+func (s *ShardedPRB[T]) Peek() (Element[T], error) {
+	_, head, ok := s.best()
+	if !ok {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	return head, nil
+}
+
+func (s *ShardedPRB[T]) Shards() int {
+	return len(s.shards)
+}
+
+func (s *ShardedPRB[T]) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Len()
+	}
+
+	return total
+}
+
+func (s *ShardedPRB[T]) Cap() int {
+	return len(s.shards) * s.shards[0].Cap()
+}
+
+func (s *ShardedPRB[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+func (s *ShardedPRB[T]) Clear() {
+	_ = s.ClearErr()
+}
+
+// ClearErr is like Clear but returns the errors of the shards' ClearErr.
+func (s *ShardedPRB[T]) ClearErr() error {
+	var errs []error
+	for _, shard := range s.shards {
+		errs = append(errs, shard.ClearErr())
+	}
+
+	return errors.Join(errs...)
+}
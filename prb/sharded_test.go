@@ -0,0 +1,67 @@
+package prb_test
+
+import (
+	"errors"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestShardedOverflowModes(t *testing.T) {
+	tests := []struct {
+		mode    prb.OverflowMode
+		want    error
+		wantLen int
+	}{
+		{prb.DropOldest, nil, 4},
+		{prb.Reject, nil, 4},
+		{prb.DropNewest, nil, 4},
+		{prb.DropLowestPriority, nil, 4},
+		{prb.Block, prb.ErrInvalidOverflowMode, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			s, err := prb.NewSharded[int](2, 2,
+				prb.WithShardOptions[int](prb.WithOverflowMode[int](tt.mode)))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("NewSharded: %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			for i := range 6 {
+				_ = s.Insert(i, i)
+			}
+			if got := s.Len(); got != tt.wantLen {
+				t.Fatalf("Len() = %d, want %d", got, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestShardedDequeueOrder(t *testing.T) {
+	s := prb.Must(prb.NewSharded[int](3, 16))
+	for i := range 30 {
+		if err := s.Insert(i, i%3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous, err := s.Dequeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 29 {
+		e, err := s.Dequeue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Priority > previous.Priority ||
+			(e.Priority == previous.Priority && e.InsertionOrder < previous.InsertionOrder) {
+			t.Fatalf("dequeued %+v after %+v", e, previous)
+		}
+		previous = e
+	}
+}
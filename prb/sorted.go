@@ -0,0 +1,160 @@
+package prb
+
+import "slices"
+
+// A bubble window spanning the whole capacity keeps the buffer fully
+// ordered, but bubbling costs up to capacity-1 moves per insert. Such a
+// buffer instead stores its elements as a binary max-heap in slots
+// [0, size), head fixed at slot zero, so inserts and removals take O(log n).
+// Dequeue order is unchanged; operations that walk the contents in order
+// sort the slot indices first.
+
+// wantsHeap reports whether the configuration calls for the heap layout.
+func (b *PriorityRingBuffer[T]) wantsHeap() bool {
+	return b.capacity > 1 && b.bubbleWindow == b.capacity-1
+}
+
+// setLayout switches between the ring and heap layouts when the bubble
+// window calls for it. Switching to the heap fully orders the contents. The
+// caller must hold the write lock and commit afterwards.
+func (b *PriorityRingBuffer[T]) setLayout() {
+	if b.wantsHeap() == b.sorted {
+		return
+	}
+
+	elements := b.state().Elements
+	b.sorted = !b.sorted
+	b.relayout(elements)
+}
+
+// slot returns the slot holding the element at logical position i.
+func (b *PriorityRingBuffer[T]) slot(i int) int {
+	if !b.sorted || i == 0 {
+		return b.wrap(b.head + i)
+	}
+
+	return b.sortedSlots()[i]
+}
+
+// position is the inverse of slot.
+func (b *PriorityRingBuffer[T]) position(slot int) int {
+	if !b.sorted {
+		return b.logical(slot)
+	}
+
+	return slices.Index(b.sortedSlots(), slot)
+}
+
+// each calls fn with the logical position and slot of every element in
+// dequeue order until fn returns false.
+func (b *PriorityRingBuffer[T]) each(fn func(i, slot int) bool) {
+	if b.sorted {
+		for i, slot := range b.sortedSlots() {
+			if !fn(i, slot) {
+				return
+			}
+		}
+		return
+	}
+
+	for i := 0; i < b.size; i++ {
+		if !fn(i, b.wrap(b.head+i)) {
+			return
+		}
+	}
+}
+
+// sortedSlots returns the occupied slots of the heap layout in dequeue order.
+func (b *PriorityRingBuffer[T]) sortedSlots() []int {
+	slots := make([]int, b.size)
+	for i := range slots {
+		slots[i] = i
+	}
+
+	slices.SortFunc(slots, func(x, y int) int {
+		switch {
+		case outranks(b.elements[x], b.elements[y]):
+			return -1
+		case outranks(b.elements[y], b.elements[x]):
+			return 1
+		}
+		return 0
+	})
+
+	return slots
+}
+
+// pushHeap appends element to the heap and sifts it up.
+func (b *PriorityRingBuffer[T]) pushHeap(element Element[T]) {
+	slot := b.size
+	b.set(slot, element)
+	b.size++
+	b.tail = b.wrap(b.size)
+	b.siftUp(slot)
+}
+
+// takeHeap removes the element in slot, filling the hole with the last
+// element of the heap.
+func (b *PriorityRingBuffer[T]) takeHeap(slot int) Element[T] {
+	element := b.elements[slot]
+	last := b.size - 1
+	b.size--
+	b.tail = b.wrap(b.size)
+
+	if slot != last {
+		b.set(slot, b.elements[last])
+		b.fixHeap(slot)
+	}
+
+	b.removed(element)
+	return element
+}
+
+// heapify restores the heap property over slots [0, size).
+func (b *PriorityRingBuffer[T]) heapify() {
+	for slot := b.size/2 - 1; slot >= 0; slot-- {
+		b.siftDown(slot)
+	}
+}
+
+// fixHeap restores the heap property after the element in slot changed.
+func (b *PriorityRingBuffer[T]) fixHeap(slot int) {
+	if slot > 0 && outranks(b.elements[slot], b.elements[(slot-1)/2]) {
+		b.siftUp(slot)
+	} else {
+		b.siftDown(slot)
+	}
+}
+
+func (b *PriorityRingBuffer[T]) siftUp(slot int) {
+	for slot > 0 {
+		parent := (slot - 1) / 2
+		if !outranks(b.elements[slot], b.elements[parent]) {
+			return
+		}
+		b.swap(slot, parent)
+		slot = parent
+	}
+}
+
+func (b *PriorityRingBuffer[T]) siftDown(slot int) {
+	for {
+		best := slot
+		for _, child := range [2]int{2*slot + 1, 2*slot + 2} {
+			if child < b.size && outranks(b.elements[child], b.elements[best]) {
+				best = child
+			}
+		}
+		if best == slot {
+			return
+		}
+		b.swap(slot, best)
+		slot = best
+	}
+}
+
+func (b *PriorityRingBuffer[T]) swap(i, j int) {
+	x, y := b.elements[i], b.elements[j]
+	b.set(i, y)
+	b.set(j, x)
+}
@@ -0,0 +1,149 @@
+package prb
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// WithSpillDir sets the directory in which the Spill overflow mode creates
+// its spill file. It defaults to os.TempDir.
+func WithSpillDir[T comparable](dir string) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.spillDir = dir
+	}
+}
+
+// spill is the file the Spill overflow mode appends elements to while the
+// ring is full. Records are read back from the front and the file is
+// truncated whenever it has been read completely.
+type spill struct {
+	file  *os.File
+	read  int64
+	write int64
+	count int
+	err   error
+}
+
+func (s *spill) append(record []byte) error {
+	frame := binary.AppendUvarint(make([]byte, 0, len(record)+binary.MaxVarintLen64), uint64(len(record)))
+	frame = append(frame, record...)
+
+	if _, err := s.file.WriteAt(frame, s.write); err != nil {
+		return err
+	}
+
+	s.write += int64(len(frame))
+	s.count++
+	return nil
+}
+
+func (s *spill) next() ([]byte, error) {
+	header := make([]byte, binary.MaxVarintLen64)
+	n, err := s.file.ReadAt(header, s.read)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	length, m := binary.Uvarint(header[:n])
+	if m <= 0 {
+		return nil, ErrInvalidFormat
+	}
+
+	record := make([]byte, length)
+	if _, err := s.file.ReadAt(record, s.read+int64(m)); err != nil {
+		return nil, err
+	}
+
+	s.read += int64(m) + int64(length)
+	s.count--
+	if s.count == 0 {
+		return record, s.reset()
+	}
+
+	return record, nil
+}
+
+// reset discards every spilled element.
+func (s *spill) reset() error {
+	s.read, s.write, s.count = 0, 0, 0
+	return s.file.Truncate(0)
+}
+
+func (s *spill) close() error {
+	return errors.Join(s.file.Close(), os.Remove(s.file.Name()))
+}
+
+// spillElement appends element to the spill file, creating it on first use.
+// The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) spillElement(element Element[T]) error {
+	if b.spill == nil {
+		file, err := os.CreateTemp(b.spillDir, "prb-spill-*")
+		if err != nil {
+			return err
+		}
+		b.spill = &spill{file: file}
+	}
+
+	if b.spill.err != nil {
+		return b.spill.err
+	}
+
+	record, err := appendElement(nil, element, b.valueCodec())
+	if err != nil {
+		return err
+	}
+	record = appendString(record, element.Key)
+	record = appendTags(record, element.Tags)
+
+	return b.spill.append(record)
+}
+
+// refill moves spilled elements back into the ring, oldest first, and then
+// elements of the overflow buffer, while there is room. A read or decode
+// error stops refilling from the spill file and makes further spilling
+// inserts fail with it. The caller must hold the write lock.
+func (b *PriorityRingBuffer[T]) refill() {
+	if (b.spill == nil && b.secondary == nil) || b.refilling {
+		return
+	}
+
+	b.refilling = true
+	defer func() { b.refilling = false }()
+	defer b.pullSecondary()
+
+	if b.spill == nil {
+		return
+	}
+
+	codec := b.valueCodec()
+	for b.spill.err == nil && b.spill.count > 0 && b.size < b.capacity {
+		record, err := b.spill.next()
+		if err != nil {
+			b.spill.err = err
+			return
+		}
+
+		d := decoder{data: record}
+		element, err := decodeElement(&d, codec)
+		element.Key = d.string()
+		element.Tags = d.tags()
+		if err == nil {
+			err = d.err
+		}
+		if err != nil {
+			b.spill.err = err
+			return
+		}
+
+		// A newer element with the same key replaced this one.
+		if b.keySlot(element.Key) >= 0 {
+			continue
+		}
+
+		if _, _, err := b.push(element); err != nil {
+			b.bury(element, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package prb_test
+
+import (
+	"os"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestSpill(t *testing.T) {
+	tests := []struct {
+		name        string
+		inserts     int
+		wantSpilled int
+	}{
+		{"fits", 2, 0},
+		{"spills", 5, 3},
+		{"spills many", 100, 98},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			b := prb.MustNew[int](2,
+				prb.WithOverflowMode[int](prb.Spill),
+				prb.WithSpillDir[int](dir))
+
+			for i := range tt.inserts {
+				if err := b.Insert(i, 0); err != nil {
+					t.Fatalf("Insert: %v", err)
+				}
+			}
+			if got := b.Len(); got != min(tt.inserts, 2) {
+				t.Fatalf("Len() = %d, want %d", got, min(tt.inserts, 2))
+			}
+			if got := b.GetStats().Spilled; got != tt.wantSpilled {
+				t.Fatalf("Spilled = %d, want %d", got, tt.wantSpilled)
+			}
+
+			for i := range tt.inserts {
+				e, err := b.Dequeue()
+				if err != nil {
+					t.Fatalf("Dequeue: %v", err)
+				}
+				if e.Value != i {
+					t.Fatalf("Dequeue() = %d, want %d", e.Value, i)
+				}
+			}
+			if !b.IsEmpty() {
+				t.Fatalf("Len() = %d after draining, want 0", b.Len())
+			}
+
+			if err := b.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if files, _ := os.ReadDir(dir); len(files) != 0 {
+				t.Fatalf("%d files left in the spill directory", len(files))
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package prb
+
+import "maps"
+
+// InsertWithMeta is Insert that attaches tags to the element. The buffer
+// keeps its own copy of tags. Memory-mapped buffers have fixed-size slots
+// and reject tagged inserts with ErrMmapMetadata.
+func (b *PriorityRingBuffer[T]) InsertWithMeta(value T, priority int, tags map[string]string) error {
+	if len(tags) == 0 {
+		return b.Insert(value, priority)
+	}
+
+	_, _, err := b.insert(Element[T]{Value: value, Priority: priority, Tags: maps.Clone(tags)}, nil, nil)
+	return err
+}
+
+// SearchByTag matches elements whose tag key is set to value.
+func SearchByTag[T comparable](key, value string) SearchFilter[T] {
+	return func(e Element[T]) bool {
+		v, ok := e.Tags[key]
+		return ok && v == value
+	}
+}
+
+// SearchByTagKey matches elements that have tag key set, to any value.
+func SearchByTagKey[T comparable](key string) SearchFilter[T] {
+	return func(e Element[T]) bool {
+		_, ok := e.Tags[key]
+		return ok
+	}
+}
@@ -0,0 +1,198 @@
+package prb
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+)
+
+var ErrInvalidHotSize = errors.New("hot tier size must be positive")
+
+// TieredPRB keeps the highest ranked elements in a small, fully sorted hot
+// tier and the rest in a cold ring, so Peek and Dequeue are O(1) while most
+// inserts only touch the cold ring. Every cold element ranks below every hot
+// one; when the hot tier runs empty it is refilled from the cold ring with
+// DequeueMax, so a large cold ring should use WithMaxIndex or a full bubble
+// window.
+type TieredPRB[T comparable] struct {
+	// hot is sorted lowest ranked first, so the best element pops off the end.
+	hot          []Element[T]
+	hotSize      int
+	cold         *PriorityRingBuffer[T]
+	coldOpts     []Option[T]
+	orderCounter int64
+	mu           sync.Mutex
+}
+
+type TieredOption[T comparable] func(*TieredPRB[T])
+
+// WithColdOptions applies opts to the cold ring. Its overflow mode, which
+// must not be Block, decides what happens once the cold ring is full.
+func WithColdOptions[T comparable](opts ...Option[T]) TieredOption[T] {
+	return func(t *TieredPRB[T]) {
+		t.coldOpts = append(t.coldOpts, opts...)
+	}
+}
+
+func NewTiered[T comparable](hotSize, coldCapacity int, opts ...TieredOption[T]) (*TieredPRB[T], error) {
+	if hotSize <= 0 {
+		return nil, ErrInvalidHotSize
+	}
+
+	t := &TieredPRB[T]{
+		hot:     make([]Element[T], 0, hotSize),
+		hotSize: hotSize,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	cold, err := New(coldCapacity, t.coldOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if cold.OverflowMode() == Block {
+		return nil, ErrInvalidOverflowMode
+	}
+	t.cold = cold
+
+	return t, nil
+}
+
+// Insert adds the element to the hot tier if it outranks the lowest hot
+// element, moving that one to the cold ring if the hot tier is full, and to
+// the cold ring otherwise. It fails if the cold ring rejects the element it
+// receives.
+func (t *TieredPRB[T]) Insert(value T, priority int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refill()
+
+	element := Element[T]{Value: value, Priority: priority, InsertionOrder: t.orderCounter}
+	hot := (len(t.hot) > 0 && outranks(element, t.hot[0])) ||
+		(len(t.hot) < t.hotSize && t.cold.Len() == 0)
+	if !hot {
+		if err := t.insertCold(element); err != nil {
+			return err
+		}
+		t.orderCounter++
+		return nil
+	}
+
+	if len(t.hot) == t.hotSize {
+		if err := t.insertCold(t.hot[0]); err != nil {
+			return err
+		}
+		t.hot = slices.Delete(t.hot, 0, 1)
+	}
+
+	i := sort.Search(len(t.hot), func(i int) bool {
+		return outranks(t.hot[i], element)
+	})
+	t.hot = slices.Insert(t.hot, i, element)
+	t.orderCounter++
+	return nil
+}
+
+// insertCold adds element to the cold ring, keeping its insertion order.
+func (t *TieredPRB[T]) insertCold(element Element[T]) error {
+	element.keepOrder = true
+	_, _, err := t.cold.insert(element, nil, nil)
+	return err
+}
+
+// refill moves the best cold elements into an empty hot tier. The caller
+// must hold the lock.
+func (t *TieredPRB[T]) refill() {
+	if len(t.hot) > 0 {
+		return
+	}
+
+	for len(t.hot) < t.hotSize {
+		element, err := t.cold.DequeueMax()
+		if err != nil {
+			break
+		}
+		t.hot = append(t.hot, element)
+	}
+
+	slices.Reverse(t.hot)
+}
+
+// Dequeue removes and returns the highest ranked element.
+func (t *TieredPRB[T]) Dequeue() (Element[T], error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refill()
+	if len(t.hot) == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	element := t.hot[len(t.hot)-1]
+	t.hot = t.hot[:len(t.hot)-1]
+	element.GuaranteedMax = true
+	return element, nil
+}
+
+// Peek returns the highest ranked element without removing it.
+func (t *TieredPRB[T]) Peek() (Element[T], error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refill()
+	if len(t.hot) == 0 {
+		return Element[T]{}, ErrBufferEmpty
+	}
+
+	element := t.hot[len(t.hot)-1]
+	element.GuaranteedMax = true
+	return element, nil
+}
+
+// PeekMaxPriority returns the element with the highest priority, which the
+// hot tier always serves first.
+func (t *TieredPRB[T]) PeekMaxPriority() (Element[T], error) {
+	return t.Peek()
+}
+
+// Hot returns a copy of the hot tier, best first.
+func (t *TieredPRB[T]) Hot() []Element[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hot := slices.Clone(t.hot)
+	slices.Reverse(hot)
+	return hot
+}
+
+func (t *TieredPRB[T]) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.hot) + t.cold.Len()
+}
+
+func (t *TieredPRB[T]) Cap() int {
+	return t.hotSize + t.cold.Cap()
+}
+
+func (t *TieredPRB[T]) IsEmpty() bool {
+	return t.Len() == 0
+}
+
+func (t *TieredPRB[T]) Clear() {
+	_ = t.ClearErr()
+}
+
+// ClearErr is like Clear but returns the error of the cold ring's ClearErr.
+func (t *TieredPRB[T]) ClearErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.hot = t.hot[:0]
+	return t.cold.ClearErr()
+}
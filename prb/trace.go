@@ -0,0 +1,34 @@
+package prb
+
+import "time"
+
+// TraceEvent describes one completed Insert, Dequeue, DequeueInto or
+// ReplaceHead.
+type TraceEvent struct {
+	Operation string
+	Start     time.Time
+	End       time.Time
+	Priority  int
+	Size      int
+	Overwrite bool
+	Err       error
+}
+
+// Tracer receives a TraceEvent after every traced operation. It is called
+// with the buffer lock held and must not call back into the buffer.
+type Tracer interface {
+	Trace(TraceEvent)
+}
+
+// WithTracer enables tracing. Without it operations skip all tracing work.
+func WithTracer[T comparable](tracer Tracer) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.tracer = tracer
+	}
+}
+
+func (b *PriorityRingBuffer[T]) trace(event TraceEvent) {
+	event.End = b.clock.Now()
+	event.Size = b.size
+	b.tracer.Trace(event)
+}
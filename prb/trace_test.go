@@ -0,0 +1,69 @@
+package prb_test
+
+import (
+	"testing"
+
+	"GoPRB/prb"
+)
+
+type recorder struct {
+	events []prb.TraceEvent
+}
+
+func (r *recorder) Trace(event prb.TraceEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestTracing(t *testing.T) {
+	tests := []struct {
+		operation    string
+		run          func(b *prb.PriorityRingBuffer[int]) error
+		wantPriority int
+		wantDequeues uint64
+	}{
+		{"insert", func(b *prb.PriorityRingBuffer[int]) error {
+			return b.Insert(3, 3)
+		}, 3, 0},
+		{"dequeue", func(b *prb.PriorityRingBuffer[int]) error {
+			_, err := b.Dequeue()
+			return err
+		}, 2, 1},
+		{"dequeueInto", func(b *prb.PriorityRingBuffer[int]) error {
+			_, err := b.DequeueInto(make([]prb.Element[int], 2))
+			return err
+		}, 2, 1},
+		{"replaceHead", func(b *prb.PriorityRingBuffer[int]) error {
+			_, err := b.ReplaceHead(0, 0)
+			return err
+		}, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			r := &recorder{}
+			b := prb.MustNew[int](4,
+				prb.WithBubbleWindow[int](3),
+				prb.WithTracer[int](r),
+				prb.WithLatencyHistograms[int]())
+			for _, p := range []int{1, 2} {
+				if err := b.Insert(p, p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			r.events = nil
+
+			if err := tt.run(b); err != nil {
+				t.Fatal(err)
+			}
+			if len(r.events) != 1 {
+				t.Fatalf("%d events traced, want 1", len(r.events))
+			}
+			if e := r.events[0]; e.Operation != tt.operation || e.Priority != tt.wantPriority {
+				t.Fatalf("traced %s with priority %d, want %s with %d", e.Operation, e.Priority, tt.operation, tt.wantPriority)
+			}
+			if got := b.GetStats().Latency.Dequeue.Count; got != tt.wantDequeues {
+				t.Fatalf("%d dequeue latencies observed, want %d", got, tt.wantDequeues)
+			}
+		})
+	}
+}
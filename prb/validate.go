@@ -0,0 +1,109 @@
+package prb
+
+import "fmt"
+
+// Validate checks the internal invariants of the buffer: ring bounds and
+// head/tail consistency, ordering of the contents when no insert was
+// truncated by the bubble window, or the heap property in the fully ordered
+// layout, insertion orders below the order counter, quota accounting and the
+// max index. It returns an error wrapping ErrInvalidState describing the
+// first violation found.
+func (b *PriorityRingBuffer[T]) Validate() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.validate()
+}
+
+func (b *PriorityRingBuffer[T]) validate() error {
+	if err := b.validateConfig(); err != nil {
+		return err
+	}
+
+	if len(b.elements) != b.capacity {
+		return fmt.Errorf("%w: %d slots for capacity %d", ErrInvalidState, len(b.elements), b.capacity)
+	}
+
+	if b.size < 0 || b.size > b.capacity {
+		return fmt.Errorf("%w: size %d outside [0, %d]", ErrInvalidState, b.size, b.capacity)
+	}
+
+	if b.head < 0 || b.head >= b.capacity || b.tail < 0 || b.tail >= b.capacity {
+		return fmt.Errorf("%w: head %d or tail %d outside the ring", ErrInvalidState, b.head, b.tail)
+	}
+
+	if b.wrap(b.head+b.size) != b.tail {
+		return fmt.Errorf("%w: head %d plus size %d does not reach tail %d", ErrInvalidState, b.head, b.size, b.tail)
+	}
+
+	if b.sorted != b.wantsHeap() || (b.sorted && (b.head != 0 || b.unordered)) {
+		return fmt.Errorf("%w: layout does not match bubble window %d", ErrInvalidState, b.bubbleWindow)
+	}
+
+	used := make([]int, len(b.quotas))
+	seen := make(map[int64]struct{}, b.size)
+	keys := make(map[string]struct{})
+	for i := 0; i < b.size; i++ {
+		e := b.elements[b.wrap(b.head+i)]
+
+		if e.InsertionOrder < 0 || e.InsertionOrder >= b.orderCounter {
+			return fmt.Errorf("%w: element %d has insertion order %d, counter is %d", ErrInvalidState, i, e.InsertionOrder, b.orderCounter)
+		}
+
+		if _, ok := seen[e.InsertionOrder]; ok {
+			return fmt.Errorf("%w: duplicate insertion order %d", ErrInvalidState, e.InsertionOrder)
+		}
+		seen[e.InsertionOrder] = struct{}{}
+
+		if e.Key != "" {
+			if _, ok := keys[e.Key]; ok {
+				return fmt.Errorf("%w: duplicate key %q", ErrInvalidState, e.Key)
+			}
+			keys[e.Key] = struct{}{}
+			if slot, ok := b.keys[e.Key]; !ok || slot != b.wrap(b.head+i) {
+				return fmt.Errorf("%w: key %q is not indexed at slot %d", ErrInvalidState, e.Key, b.wrap(b.head+i))
+			}
+		}
+
+		if b.sorted {
+			if i > 0 && outranks(e, b.elements[(i-1)/2]) {
+				return fmt.Errorf("%w: slot %d outranks its heap parent", ErrInvalidState, i)
+			}
+		} else if i > 0 && !b.unordered && outranks(e, b.elements[b.wrap(b.head+i-1)]) {
+			return fmt.Errorf("%w: element %d outranks its predecessor", ErrInvalidState, i)
+		}
+
+		for j := range b.quotas {
+			if b.quotas[j].covers(e.Priority) {
+				used[j]++
+			}
+		}
+	}
+
+	if len(b.keys) != len(keys) {
+		return fmt.Errorf("%w: key index holds %d keys, buffer %d", ErrInvalidState, len(b.keys), len(keys))
+	}
+
+	for j, q := range b.quotas {
+		if q.used != used[j] {
+			return fmt.Errorf("%w: quota %d counts %d elements, holds %d", ErrInvalidState, j, q.used, used[j])
+		}
+	}
+
+	if b.heap != nil {
+		if len(b.heap) != b.size {
+			return fmt.Errorf("%w: max index holds %d slots for size %d", ErrInvalidState, len(b.heap), b.size)
+		}
+
+		for pos, slot := range b.heap {
+			if b.heapPos[slot] != pos || b.logical(slot) >= b.size {
+				return fmt.Errorf("%w: max index entry %d is stale", ErrInvalidState, pos)
+			}
+			if pos > 0 && b.heapLess(pos, (pos-1)/2) {
+				return fmt.Errorf("%w: max index out of heap order at %d", ErrInvalidState, pos)
+			}
+		}
+	}
+
+	return nil
+}
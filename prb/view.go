@@ -0,0 +1,43 @@
+package prb
+
+// ReadOnlyBuffer is the read side of a buffer. Both the view returned by
+// View and a published ReadView implement it.
+type ReadOnlyBuffer[T comparable] interface {
+	Peek() (Element[T], error)
+	PeekMaxPriority() (Element[T], error)
+	Search(filters ...SearchFilter[T]) []int
+	Len() int
+	Cap() int
+}
+
+// readOnly forwards reads to a live buffer. It does not embed the buffer so
+// that holders cannot type assert their way to its mutators.
+type readOnly[T comparable] struct {
+	buffer *PriorityRingBuffer[T]
+}
+
+// View returns a live, read-only handle to the buffer, for code such as
+// monitoring that must not mutate it.
+func (b *PriorityRingBuffer[T]) View() ReadOnlyBuffer[T] {
+	return readOnly[T]{buffer: b}
+}
+
+func (v readOnly[T]) Peek() (Element[T], error) {
+	return v.buffer.Peek()
+}
+
+func (v readOnly[T]) PeekMaxPriority() (Element[T], error) {
+	return v.buffer.PeekMaxPriority()
+}
+
+func (v readOnly[T]) Search(filters ...SearchFilter[T]) []int {
+	return v.buffer.Search(filters...)
+}
+
+func (v readOnly[T]) Len() int {
+	return v.buffer.Len()
+}
+
+func (v readOnly[T]) Cap() int {
+	return v.buffer.Cap()
+}
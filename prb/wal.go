@@ -0,0 +1,644 @@
+package prb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs the log after every record.
+	SyncAlways SyncPolicy = iota
+	// SyncNever leaves flushing to the operating system and Close.
+	SyncNever
+)
+
+const (
+	walState byte = iota
+	walInsert
+	walDequeue
+	walClear
+	walDequeueMax
+	walDequeueBatch
+	walRemoveMin
+	walReplaceHead
+	walCompact
+	walRemoveAt
+	walSetBubbleWindow
+	walInsertMeta
+	walSetOverflowMode
+	walShrink
+	walCompressed
+	walEncrypted
+)
+
+// Logs start with walMagic and walVersion, and every record is framed by its
+// length and followed by its checksum. Logs without the header come from
+// before checksums and have no checksums until they are compacted.
+const (
+	walMagic   = "GPRW"
+	walVersion = 1
+)
+
+type wal struct {
+	file    *os.File
+	path    string
+	policy  SyncPolicy
+	checked bool
+	seq     sequence
+}
+
+func WithWALSync[T comparable](policy SyncPolicy) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.walSync = policy
+	}
+}
+
+// OpenFromWAL replays the log at path into a new buffer and keeps appending
+// every mutation to it. When the log does not exist yet it is created and
+// seeded with the buffer built from capacity and opts; otherwise the logged
+// configuration wins. A torn record at the end of the log is discarded.
+func OpenFromWAL[T comparable](path string, capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
+	b, valid, checked, seq, err := replayFile(path, capacity, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if valid == 0 {
+		if seq, err = newSequence(); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.Truncate(int64(valid)); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(int64(valid), 0); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	b.wal = &wal{file: file, path: path, policy: b.walSync, checked: valid == 0 || checked, seq: seq}
+	if valid == 0 {
+		err := b.wal.writeHeader()
+		if err == nil {
+			err = b.logState(b.state())
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// ReplayWAL rebuilds the buffer recorded in the log at path like
+// OpenFromWAL, but leaves the log untouched and does not append to it.
+func ReplayWAL[T comparable](path string, capacity int, opts ...Option[T]) (*PriorityRingBuffer[T], error) {
+	b, _, _, _, err := replayFile(path, capacity, opts)
+	return b, err
+}
+
+// replayFile builds a buffer from capacity and opts and replays the log at
+// path, if it exists, into it. It returns the length of the valid prefix,
+// whether the log has checksums and the position after its last encrypted
+// record.
+func replayFile[T comparable](path string, capacity int, opts []Option[T]) (*PriorityRingBuffer[T], int, bool, sequence, error) {
+	var seq sequence
+	b, err := New(capacity, opts...)
+	if err != nil {
+		return nil, 0, false, seq, err
+	}
+
+	if b.secondary != nil {
+		return nil, 0, false, seq, ErrChainedLog
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, false, seq, err
+	}
+
+	header := len(walMagic) + 1
+	checked := bytes.HasPrefix(data, []byte(walMagic))
+	switch {
+	case checked && len(data) < header:
+		// The header itself was torn.
+		return b, 0, false, seq, nil
+	case checked && data[len(walMagic)] != walVersion:
+		return nil, 0, false, seq, ErrUnsupportedVersion
+	case checked:
+		data = data[header:]
+	case bytes.HasPrefix([]byte(walMagic), data):
+		return b, 0, false, seq, nil
+	}
+
+	// Logged inserts already passed the rate limit, window adaptation is in
+	// the log as well, and dead letters were handed out the first time.
+	limiter, adaptive, deadLetter := b.limiter, b.adaptive, b.deadLetter
+	b.limiter, b.adaptive, b.deadLetter = nil, nil, nil
+	// Skipped corruption is reported with offsets from the start of the file.
+	report := b.onCorruption
+	if checked && report != nil {
+		b.onCorruption = func(err *CorruptionError) {
+			err.Offset += int64(header)
+			report(err)
+		}
+	}
+	valid, err := b.replay(data, checked, &seq)
+	b.limiter, b.adaptive, b.deadLetter = limiter, adaptive, deadLetter
+	b.onCorruption = report
+	if err != nil {
+		if checked {
+			err = shiftCorruption(err, header)
+		}
+		return nil, 0, false, seq, err
+	}
+	if adaptive != nil {
+		adaptive.fit(b.capacity)
+	}
+
+	if checked {
+		valid += header
+	}
+
+	return b, valid, checked, seq, nil
+}
+
+// replay applies every complete record in data and returns the length of the
+// valid prefix. Records in checked logs are followed by their checksum; a
+// bad checksum on the last record is taken for a torn write. seq is set for
+// logs, as opposed to replication streams: if the buffer has a key provider
+// every record must then be encrypted and follow seq, which is advanced.
+func (b *PriorityRingBuffer[T]) replay(data []byte, checked bool, seq *sequence) (int, error) {
+	codec := b.valueCodec()
+	valid := 0
+
+	trailer := 0
+	if checked {
+		trailer = checksumLen
+	}
+
+	for valid < len(data) {
+		length, n := binary.Uvarint(data[valid:])
+		if n <= 0 || len(data)-valid-n < trailer || uint64(len(data)-valid-n-trailer) < length {
+			break
+		}
+
+		record := data[valid+n : valid+n+int(length)]
+		if checked && !checksumValid(record, data[valid+n+int(length):valid+n+int(length)+trailer]) {
+			if valid+n+int(length)+trailer == len(data) {
+				break
+			}
+
+			err := &CorruptionError{Offset: int64(valid), Err: ErrChecksum}
+			if report := b.onCorrupt(); report != nil {
+				report(err)
+				break
+			}
+			return 0, err
+		}
+
+		if len(record) == 0 {
+			return 0, ErrInvalidFormat
+		}
+
+		if seq != nil && b.keyProvider != nil && record[0] != walEncrypted {
+			err := &CorruptionError{Offset: int64(valid), Err: ErrUnencrypted}
+			if report := b.onCorrupt(); report != nil {
+				report(err)
+				break
+			}
+			return 0, err
+		}
+
+		wrapped := record[0] == walEncrypted || record[0] == walCompressed
+		if record[0] == walEncrypted {
+			var err error
+			var at sequence
+			if record, at, err = b.decryptRecord(record); err != nil {
+				return 0, err
+			}
+
+			if seq != nil && (at.next != seq.next || (at.next > 0 && at.log != seq.log)) {
+				err := &CorruptionError{Offset: int64(valid), Err: ErrRecordSequence}
+				if report := b.onCorrupt(); report != nil {
+					report(err)
+					break
+				}
+				return 0, err
+			}
+			if seq != nil {
+				seq.log, seq.next = at.log, at.next+1
+			}
+		}
+
+		if record[0] == walCompressed {
+			var err error
+			if record, err = b.decompressRecord(record); err != nil {
+				return 0, err
+			}
+		}
+
+		switch record[0] {
+		case walState:
+			// Offsets into a decrypted or decompressed record can only point
+			// at the record.
+			locate := func(corrupt *CorruptionError) {
+				if wrapped {
+					corrupt.Offset = int64(valid)
+				} else {
+					corrupt.Offset += int64(valid + n + 1)
+				}
+			}
+			onCorrupt := b.onCorrupt()
+			if report := onCorrupt; report != nil {
+				onCorrupt = func(err *CorruptionError) {
+					locate(err)
+					report(err)
+				}
+			}
+
+			s, err := decodeState(record[1:], codec, onCorrupt)
+			var corrupt *CorruptionError
+			if errors.As(err, &corrupt) {
+				locate(corrupt)
+			}
+			if err != nil {
+				return 0, err
+			}
+			if err := b.load(s); err != nil {
+				return 0, err
+			}
+		case walInsert:
+			d := decoder{data: record[1:]}
+			priority := int(d.varint())
+			value, err := codec.DecodeValue(d.data)
+			if d.err != nil {
+				return 0, d.err
+			}
+			if err != nil {
+				return 0, err
+			}
+			_ = b.Insert(value, priority)
+		case walInsertMeta:
+			d := decoder{data: record[1:]}
+			element := Element[T]{Priority: int(d.varint())}
+			element.Key = d.string()
+			element.Tags = d.tags()
+			value, err := codec.DecodeValue(d.data)
+			if d.err != nil {
+				return 0, d.err
+			}
+			if err != nil {
+				return 0, err
+			}
+			element.Value = value
+			_, _, _ = b.insert(element, nil, nil)
+		case walDequeue:
+			_, _ = b.Dequeue()
+		case walClear:
+			b.Clear()
+		case walDequeueMax:
+			_, _ = b.DequeueMax()
+		case walDequeueBatch:
+			d := decoder{data: record[1:]}
+			n := d.uvarint()
+			if d.err != nil {
+				return 0, d.err
+			}
+			for range n {
+				_, _ = b.Dequeue()
+			}
+		case walRemoveMin:
+			_, _ = b.RemoveMin()
+		case walReplaceHead:
+			d := decoder{data: record[1:]}
+			priority := int(d.varint())
+			value, err := codec.DecodeValue(d.data)
+			if d.err != nil {
+				return 0, d.err
+			}
+			if err != nil {
+				return 0, err
+			}
+			_, _ = b.ReplaceHead(value, priority)
+		case walCompact:
+			d := decoder{data: record[1:]}
+			resort := d.byte() != 0
+			if d.err != nil {
+				return 0, d.err
+			}
+			_ = b.Compact(resort)
+		case walRemoveAt:
+			d := decoder{data: record[1:]}
+			i := d.uvarint()
+			if d.err != nil {
+				return 0, d.err
+			}
+			_, _ = b.RemoveAt(int(i))
+		case walSetBubbleWindow:
+			d := decoder{data: record[1:]}
+			window := d.uvarint()
+			if d.err != nil {
+				return 0, d.err
+			}
+			_ = b.SetBubbleWindow(int(window))
+		case walSetOverflowMode:
+			d := decoder{data: record[1:]}
+			mode := OverflowMode(d.byte())
+			if d.err != nil {
+				return 0, d.err
+			}
+			_ = b.SetOverflowMode(mode)
+		case walShrink:
+			d := decoder{data: record[1:]}
+			capacity := d.uvarint()
+			if d.err != nil {
+				return 0, d.err
+			}
+			_ = b.ShrinkTo(int(capacity))
+		default:
+			return 0, ErrInvalidFormat
+		}
+
+		valid += n + int(length) + trailer
+	}
+
+	return valid, nil
+}
+
+func (w *wal) writeHeader() error {
+	_, err := w.file.Write(append([]byte(walMagic), walVersion))
+	return err
+}
+
+func (w *wal) append(record []byte) error {
+	frame := binary.AppendUvarint(make([]byte, 0, len(record)+binary.MaxVarintLen64+checksumLen), uint64(len(record)))
+	frame = append(frame, record...)
+	if w.checked {
+		frame = appendChecksum(frame, record)
+	}
+
+	if _, err := w.file.Write(frame); err != nil {
+		return err
+	}
+
+	if w.policy == SyncAlways {
+		return w.file.Sync()
+	}
+
+	return nil
+}
+
+// logging reports whether mutations need to be encoded, for the
+// write-ahead log or for replication.
+func (b *PriorityRingBuffer[T]) logging() bool {
+	return b.wal != nil || len(b.replicas) > 0
+}
+
+// appendRecord appends record to the write-ahead log, if any, and passes it
+// to every replica that has not failed.
+func (b *PriorityRingBuffer[T]) appendRecord(record []byte) error {
+	// Records that only go to replicas are not part of a log.
+	seq := &sequence{}
+	if b.wal != nil {
+		seq = &b.wal.seq
+	}
+
+	record, err := b.sealRecord(record, seq)
+	if err != nil {
+		return err
+	}
+
+	if b.wal != nil {
+		if err := b.wal.append(record); err != nil {
+			return err
+		}
+	}
+
+	for _, replica := range b.replicas {
+		if replica.err == nil {
+			replica.err = replica.send(record)
+		}
+	}
+
+	return nil
+}
+
+// sealRecord compresses and encrypts record as the buffer is configured to,
+// numbering it by seq if it is encrypted.
+func (b *PriorityRingBuffer[T]) sealRecord(record []byte, seq *sequence) ([]byte, error) {
+	record, err := b.compressRecord(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.encryptRecord(record, seq)
+}
+
+func (b *PriorityRingBuffer[T]) logOp(op byte) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord([]byte{op})
+}
+
+func (b *PriorityRingBuffer[T]) logInsert(e Element[T]) error {
+	if e.Key == "" && len(e.Tags) == 0 {
+		return b.logValue(walInsert, e.Value, e.Priority)
+	}
+
+	if !b.logging() {
+		return nil
+	}
+
+	record := binary.AppendVarint([]byte{walInsertMeta}, int64(e.Priority))
+	record = appendString(record, e.Key)
+	record = appendTags(record, e.Tags)
+	record, err := b.valueCodec().AppendValue(record, e.Value)
+	if err != nil {
+		return err
+	}
+
+	return b.appendRecord(record)
+}
+
+func (b *PriorityRingBuffer[T]) logReplaceHead(value T, priority int) error {
+	return b.logValue(walReplaceHead, value, priority)
+}
+
+func (b *PriorityRingBuffer[T]) logValue(op byte, value T, priority int) error {
+	if !b.logging() {
+		return nil
+	}
+
+	record := binary.AppendVarint([]byte{op}, int64(priority))
+	record, err := b.valueCodec().AppendValue(record, value)
+	if err != nil {
+		return err
+	}
+
+	return b.appendRecord(record)
+}
+
+func (b *PriorityRingBuffer[T]) logDequeueBatch(n int) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord(binary.AppendUvarint([]byte{walDequeueBatch}, uint64(n)))
+}
+
+func (b *PriorityRingBuffer[T]) logCompact(resort bool) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord(appendBool([]byte{walCompact}, resort))
+}
+
+func (b *PriorityRingBuffer[T]) logRemoveAt(i int) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord(binary.AppendUvarint([]byte{walRemoveAt}, uint64(i)))
+}
+
+func (b *PriorityRingBuffer[T]) logSetBubbleWindow(window int) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord(binary.AppendUvarint([]byte{walSetBubbleWindow}, uint64(window)))
+}
+
+func (b *PriorityRingBuffer[T]) logSetOverflowMode(mode OverflowMode) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord([]byte{walSetOverflowMode, byte(mode)})
+}
+
+func (b *PriorityRingBuffer[T]) logShrink(capacity int) error {
+	if !b.logging() {
+		return nil
+	}
+
+	return b.appendRecord(binary.AppendUvarint([]byte{walShrink}, uint64(capacity)))
+}
+
+func (b *PriorityRingBuffer[T]) logState(s Snapshot[T]) error {
+	record, err := appendState([]byte{walState}, s, b.valueCodec())
+	if err != nil {
+		return err
+	}
+
+	return b.appendRecord(record)
+}
+
+// CompactWAL rewrites the log as a single record holding the current state.
+func (b *PriorityRingBuffer[T]) CompactWAL() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	if b.wal == nil {
+		return nil
+	}
+
+	tmp := b.wal.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	seq, err := newSequence()
+	if err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	compacted := &wal{file: file, path: b.wal.path, policy: b.wal.policy, checked: true, seq: seq}
+	previous := b.wal
+	b.wal = compacted
+
+	err = compacted.writeHeader()
+	if err == nil {
+		err = b.logState(b.state())
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, compacted.path)
+	}
+	if err != nil {
+		b.wal = previous
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	previous.file.Close()
+	return nil
+}
+
+// Close flushes and releases the write-ahead log and memory mapping, if any,
+// and closes the event stream. Afterwards every mutation, including a second
+// Close, fails with ErrClosed; reads keep working on the in-memory contents.
+func (b *PriorityRingBuffer[T]) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	var errs []error
+	if b.store != nil {
+		errs = append(errs, b.store.sync(), b.store.close())
+		b.store = nil
+	}
+
+	if b.wal != nil {
+		errs = append(errs, b.wal.file.Sync(), b.wal.file.Close())
+	}
+
+	if b.spill != nil {
+		errs = append(errs, b.spill.close())
+		b.spill = nil
+	}
+
+	if b.events != nil {
+		close(b.events)
+		b.events = nil
+	}
+
+	for id := range b.deliveries {
+		b.settle(id)
+	}
+
+	b.closed = true
+	b.notify()
+	return errors.Join(errs...)
+}
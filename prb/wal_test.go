@@ -0,0 +1,162 @@
+package prb_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+func TestWALReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(b *prb.PriorityRingBuffer[string]) error
+	}{
+		{"inserts", func(b *prb.PriorityRingBuffer[string]) error {
+			return b.Insert("d", 0)
+		}},
+		{"dequeue", func(b *prb.PriorityRingBuffer[string]) error {
+			_, err := b.Dequeue()
+			return err
+		}},
+		{"dequeue max", func(b *prb.PriorityRingBuffer[string]) error {
+			_, err := b.DequeueMax()
+			return err
+		}},
+		{"dequeue into", func(b *prb.PriorityRingBuffer[string]) error {
+			_, err := b.DequeueInto(make([]prb.Element[string], 2))
+			return err
+		}},
+		{"remove at", func(b *prb.PriorityRingBuffer[string]) error {
+			_, err := b.RemoveAt(1)
+			return err
+		}},
+		{"replace head", func(b *prb.PriorityRingBuffer[string]) error {
+			_, err := b.ReplaceHead("z", 0)
+			return err
+		}},
+		{"clear", func(b *prb.PriorityRingBuffer[string]) error {
+			if err := b.ClearErr(); err != nil {
+				return err
+			}
+			return b.Insert("e", 1)
+		}},
+		{"overflow", func(b *prb.PriorityRingBuffer[string]) error {
+			for i := range 6 {
+				if err := b.Insert("f", i); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"settings", func(b *prb.PriorityRingBuffer[string]) error {
+			if err := b.SetBubbleWindow(0); err != nil {
+				return err
+			}
+			return b.SetOverflowMode(prb.DropNewest)
+		}},
+		{"compact", func(b *prb.PriorityRingBuffer[string]) error {
+			return b.Compact(true)
+		}},
+		{"compacted log", func(b *prb.PriorityRingBuffer[string]) error {
+			if err := b.CompactWAL(); err != nil {
+				return err
+			}
+			return b.Insert("h", 4)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "wal")
+			b, err := prb.OpenFromWAL[string](path, 4, prb.WithBubbleWindow[string](3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range []string{"a", "b", "c"} {
+				if err := b.Insert(v, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tt.mutate(b); err != nil {
+				t.Fatal(err)
+			}
+			want, wantMode := contents(b), b.OverflowMode()
+			if err := b.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			replayed, err := prb.ReplayWAL[string](path, 4)
+			if err != nil {
+				t.Fatalf("ReplayWAL: %v", err)
+			}
+			if got := contents(replayed); !slices.Equal(got, want) {
+				t.Fatalf("replayed %v, want %v", got, want)
+			}
+			if got := replayed.OverflowMode(); got != wantMode {
+				t.Fatalf("replayed overflow mode %v, want %v", got, wantMode)
+			}
+
+			// Reopening keeps appending to the same log.
+			reopened, err := prb.OpenFromWAL[string](path, 4)
+			if err != nil {
+				t.Fatalf("OpenFromWAL: %v", err)
+			}
+			if err := reopened.Insert("i", 5); err != nil {
+				t.Fatal(err)
+			}
+			want = contents(reopened)
+			if err := reopened.Close(); err != nil {
+				t.Fatal(err)
+			}
+			replayed, err = prb.ReplayWAL[string](path, 4)
+			if err != nil {
+				t.Fatalf("ReplayWAL after reopening: %v", err)
+			}
+			if got := contents(replayed); !slices.Equal(got, want) {
+				t.Fatalf("replayed %v after reopening, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWALTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal")
+	b, err := prb.OpenFromWAL[string](path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []string{"a", "b"} {
+		if err := b.Insert(v, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = prb.OpenFromWAL[string](path, 4)
+	if err != nil {
+		t.Fatalf("OpenFromWAL: %v", err)
+	}
+	if got := b.Len(); got != 1 {
+		t.Fatalf("Len() = %d, want 1", got)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ClearErr(); !errors.Is(err, prb.ErrClosed) {
+		t.Fatalf("ClearErr: %v, want ErrClosed", err)
+	}
+}
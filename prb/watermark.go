@@ -0,0 +1,52 @@
+package prb
+
+import "errors"
+
+var ErrInvalidWatermarks = errors.New("watermarks must satisfy 0 <= low < high <= 1")
+
+type watermarks struct {
+	high  float64
+	low   float64
+	above bool
+}
+
+// WithWatermarks emits EventHighWatermark once occupancy, the fraction of
+// capacity in use, rises to high and EventLowWatermark once it falls back to
+// low, so producers can shed load before inserts start failing. Each event
+// fires once per crossing.
+func WithWatermarks[T comparable](high, low float64) Option[T] {
+	return func(b *PriorityRingBuffer[T]) {
+		b.watermarks = &watermarks{high: high, low: low}
+	}
+}
+
+// AboveHighWatermark reports whether occupancy reached the high watermark
+// and has not yet fallen back to the low one.
+func (b *PriorityRingBuffer[T]) AboveHighWatermark() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.watermarks != nil && b.watermarks.above
+}
+
+func (w *watermarks) valid() bool {
+	return w.low >= 0 && w.low < w.high && w.high <= 1
+}
+
+// checkWatermarks emits a watermark event if the last mutation crossed one.
+func (b *PriorityRingBuffer[T]) checkWatermarks() {
+	w := b.watermarks
+	if w == nil {
+		return
+	}
+
+	occupancy := float64(b.size) / float64(b.capacity)
+	switch {
+	case !w.above && occupancy >= w.high:
+		w.above = true
+		b.emit(EventHighWatermark, Element[T]{}, nil)
+	case w.above && occupancy <= w.low:
+		w.above = false
+		b.emit(EventLowWatermark, Element[T]{}, nil)
+	}
+}
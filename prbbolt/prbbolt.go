@@ -0,0 +1,417 @@
+// Package prbbolt stores a priority ring buffer in a bbolt database. Every
+// operation runs in its own transaction, trading throughput for durability.
+package prbbolt
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"GoPRB/prb"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var ErrCorrupt = errors.New("stored buffer metadata is missing or corrupt")
+
+var (
+	metaBucket     = []byte("meta")
+	elementsBucket = []byte("elements")
+
+	keyCapacity     = []byte("capacity")
+	keyBubbleWindow = []byte("bubbleWindow")
+	keyGuard        = []byte("overwriteGuard")
+	keyHead         = []byte("head")
+	keyTail         = []byte("tail")
+	keySize         = []byte("size")
+	keyOrder        = []byte("orderCounter")
+)
+
+type Buffer[T comparable] struct {
+	db             *bolt.DB
+	bucket         []byte
+	codec          prb.Codec[T]
+	capacity       int
+	bubbleWindow   int
+	overwriteGuard bool
+}
+
+type Option[T comparable] func(*Buffer[T])
+
+func WithBubbleWindow[T comparable](window int) Option[T] {
+	return func(b *Buffer[T]) {
+		b.bubbleWindow = window
+	}
+}
+
+func WithOverwriteGuard[T comparable](guard bool) Option[T] {
+	return func(b *Buffer[T]) {
+		b.overwriteGuard = guard
+	}
+}
+
+func WithCodec[T comparable](codec prb.Codec[T]) Option[T] {
+	return func(b *Buffer[T]) {
+		b.codec = codec
+	}
+}
+
+// WithBucket sets the top-level bucket holding the buffer, so several
+// buffers can share one database. It defaults to "prb".
+func WithBucket[T comparable](name string) Option[T] {
+	return func(b *Buffer[T]) {
+		b.bucket = []byte(name)
+	}
+}
+
+// Open attaches a buffer to db. When the bucket already holds a buffer its
+// stored configuration is used and capacity and sizing options are ignored.
+func Open[T comparable](db *bolt.DB, capacity int, opts ...Option[T]) (*Buffer[T], error) {
+	b := &Buffer[T]{
+		db:       db,
+		bucket:   []byte("prb"),
+		codec:    prb.DefaultCodec[T]{},
+		capacity: capacity,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(b.bucket)
+		if err != nil {
+			return err
+		}
+
+		if meta := root.Bucket(metaBucket); meta != nil {
+			return b.loadConfig(meta)
+		}
+
+		if b.capacity <= 0 {
+			return prb.ErrInvalidCapacity
+		}
+		if b.bubbleWindow < 0 || b.bubbleWindow > b.capacity-1 {
+			return prb.ErrInvalidWindow
+		}
+
+		meta, err := root.CreateBucket(metaBucket)
+		if err != nil {
+			return err
+		}
+		if _, err := root.CreateBucket(elementsBucket); err != nil {
+			return err
+		}
+
+		guard := int64(0)
+		if b.overwriteGuard {
+			guard = 1
+		}
+
+		for key, value := range map[string]int64{
+			string(keyCapacity):     int64(b.capacity),
+			string(keyBubbleWindow): int64(b.bubbleWindow),
+			string(keyGuard):        guard,
+			string(keyHead):         0,
+			string(keyTail):         0,
+			string(keySize):         0,
+			string(keyOrder):        0,
+		} {
+			if err := putInt(meta, []byte(key), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *Buffer[T]) loadConfig(meta *bolt.Bucket) error {
+	capacity, ok1 := getInt(meta, keyCapacity)
+	window, ok2 := getInt(meta, keyBubbleWindow)
+	guard, ok3 := getInt(meta, keyGuard)
+	if !ok1 || !ok2 || !ok3 || capacity <= 0 {
+		return ErrCorrupt
+	}
+
+	b.capacity = int(capacity)
+	b.bubbleWindow = int(window)
+	b.overwriteGuard = guard != 0
+	return nil
+}
+
+// ring is the mutable view of one transaction.
+type ring[T comparable] struct {
+	*Buffer[T]
+	meta     *bolt.Bucket
+	elements *bolt.Bucket
+	head     int
+	tail     int
+	size     int
+	order    int64
+}
+
+func (b *Buffer[T]) open(tx *bolt.Tx) (*ring[T], error) {
+	root := tx.Bucket(b.bucket)
+	if root == nil {
+		return nil, ErrCorrupt
+	}
+
+	r := &ring[T]{
+		Buffer:   b,
+		meta:     root.Bucket(metaBucket),
+		elements: root.Bucket(elementsBucket),
+	}
+	if r.meta == nil || r.elements == nil {
+		return nil, ErrCorrupt
+	}
+
+	head, ok1 := getInt(r.meta, keyHead)
+	tail, ok2 := getInt(r.meta, keyTail)
+	size, ok3 := getInt(r.meta, keySize)
+	order, ok4 := getInt(r.meta, keyOrder)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, ErrCorrupt
+	}
+
+	r.head, r.tail, r.size, r.order = int(head), int(tail), int(size), order
+	return r, nil
+}
+
+func (r *ring[T]) save() error {
+	for key, value := range map[string]int64{
+		string(keyHead):  int64(r.head),
+		string(keyTail):  int64(r.tail),
+		string(keySize):  int64(r.size),
+		string(keyOrder): r.order,
+	} {
+		if err := putInt(r.meta, []byte(key), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ring[T]) get(index int) (prb.Element[T], error) {
+	var e prb.Element[T]
+
+	data := r.elements.Get(slotKey(index))
+	priority, n := binary.Varint(data)
+	if n <= 0 {
+		return e, ErrCorrupt
+	}
+	order, m := binary.Varint(data[n:])
+	if m <= 0 {
+		return e, ErrCorrupt
+	}
+
+	value, err := r.codec.DecodeValue(data[n+m:])
+	if err != nil {
+		return e, err
+	}
+
+	e.Value = value
+	e.Priority = int(priority)
+	e.InsertionOrder = order
+	return e, nil
+}
+
+func (r *ring[T]) set(index int, e prb.Element[T]) error {
+	data := binary.AppendVarint(nil, int64(e.Priority))
+	data = binary.AppendVarint(data, e.InsertionOrder)
+
+	data, err := r.codec.AppendValue(data, e.Value)
+	if err != nil {
+		return err
+	}
+
+	return r.elements.Put(slotKey(index), data)
+}
+
+func (b *Buffer[T]) Insert(value T, priority int) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		r, err := b.open(tx)
+		if err != nil {
+			return err
+		}
+
+		element := prb.Element[T]{
+			Value:          value,
+			Priority:       priority,
+			InsertionOrder: r.order,
+		}
+		r.order++
+
+		if r.size == r.capacity {
+			head, err := r.get(r.head)
+			if err != nil {
+				return err
+			}
+			if r.overwriteGuard && priority <= head.Priority {
+				return &prb.FullError{
+					Priority:           priority,
+					HeadPriority:       head.Priority,
+					HeadInsertionOrder: head.InsertionOrder,
+				}
+			}
+			r.head = (r.head + 1) % r.capacity
+			r.size--
+		}
+
+		index := r.tail
+		r.tail = (r.tail + 1) % r.capacity
+		r.size++
+
+		for i := 0; i < min(r.bubbleWindow, r.size-1); i++ {
+			previousIndex := (index - 1 + r.capacity) % r.capacity
+			previous, err := r.get(previousIndex)
+			if err != nil {
+				return err
+			}
+			if !before(element, previous) {
+				break
+			}
+
+			if err := r.set(index, previous); err != nil {
+				return err
+			}
+			index = previousIndex
+		}
+
+		if err := r.set(index, element); err != nil {
+			return err
+		}
+
+		return r.save()
+	})
+}
+
+func before[T comparable](current, previous prb.Element[T]) bool {
+	return current.Priority > previous.Priority ||
+		(current.Priority == previous.Priority && current.InsertionOrder < previous.InsertionOrder)
+}
+
+func (b *Buffer[T]) Dequeue() (prb.Element[T], error) {
+	var element prb.Element[T]
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		r, err := b.open(tx)
+		if err != nil {
+			return err
+		}
+		if r.size == 0 {
+			return prb.ErrBufferEmpty
+		}
+
+		if element, err = r.get(r.head); err != nil {
+			return err
+		}
+		if err := r.elements.Delete(slotKey(r.head)); err != nil {
+			return err
+		}
+
+		r.head = (r.head + 1) % r.capacity
+		r.size--
+		return r.save()
+	})
+
+	return element, err
+}
+
+func (b *Buffer[T]) Peek() (prb.Element[T], error) {
+	var element prb.Element[T]
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		r, err := b.open(tx)
+		if err != nil {
+			return err
+		}
+		if r.size == 0 {
+			return prb.ErrBufferEmpty
+		}
+
+		element, err = r.get(r.head)
+		return err
+	})
+
+	return element, err
+}
+
+func (b *Buffer[T]) Snapshot() ([]prb.Element[T], error) {
+	var result []prb.Element[T]
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		r, err := b.open(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < r.size; i++ {
+			e, err := r.get((r.head + i) % r.capacity)
+			if err != nil {
+				return err
+			}
+			result = append(result, e)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (b *Buffer[T]) Len() int {
+	size := 0
+
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		r, err := b.open(tx)
+		if err == nil {
+			size = r.size
+		}
+		return err
+	})
+
+	return size
+}
+
+func (b *Buffer[T]) Cap() int {
+	return b.capacity
+}
+
+func (b *Buffer[T]) Clear() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		r, err := b.open(tx)
+		if err != nil {
+			return err
+		}
+
+		root := tx.Bucket(b.bucket)
+		if err := root.DeleteBucket(elementsBucket); err != nil {
+			return err
+		}
+		if _, err := root.CreateBucket(elementsBucket); err != nil {
+			return err
+		}
+
+		r.head, r.tail, r.size = 0, 0, 0
+		return r.save()
+	})
+}
+
+func slotKey(index int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(index))
+}
+
+func putInt(bucket *bolt.Bucket, key []byte, value int64) error {
+	return bucket.Put(key, binary.AppendVarint(nil, value))
+}
+
+func getInt(bucket *bolt.Bucket, key []byte) (int64, bool) {
+	value, n := binary.Varint(bucket.Get(key))
+	return value, n > 0
+}
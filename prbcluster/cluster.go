@@ -0,0 +1,145 @@
+// Package prbcluster spreads one logical buffer over several nodes.
+package prbcluster
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"GoPRB/prb"
+)
+
+var ErrNoNodes = errors.New("cluster needs at least one node")
+
+// virtualNodes is how many points each node has on the hash ring.
+const virtualNodes = 128
+
+// Node is a buffer the cluster can route to, such as a
+// *prb.PriorityRingBuffer or a *prbgrpc.Client.
+type Node[T comparable] interface {
+	Insert(value T, priority int) error
+	InsertKeyed(key string, value T, priority int) error
+	Dequeue() (prb.Element[T], error)
+	Peek() (prb.Element[T], error)
+}
+
+// Cluster routes keyed inserts to a node by consistent hashing, so each key
+// stays on one node and adding a node moves few keys, and spreads unkeyed
+// inserts round robin. Dequeue and Peek merge the nodes by priority: the
+// node with the highest priority head is chosen, rotating among ties. Across
+// nodes the order is best effort, since another client may dequeue between
+// the peek and the dequeue.
+type Cluster[T comparable] struct {
+	nodes []Node[T]
+	ring  []point
+
+	mu   sync.Mutex
+	next int
+}
+
+type point struct {
+	hash uint64
+	node int
+}
+
+func New[T comparable](nodes ...Node[T]) (*Cluster[T], error) {
+	if len(nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	c := &Cluster[T]{nodes: nodes}
+	for i := range nodes {
+		for v := range virtualNodes {
+			c.ring = append(c.ring, point{hash: hash(fmt.Sprintf("%d-%d", i, v)), node: i})
+		}
+	}
+	slices.SortFunc(c.ring, func(a, b point) int {
+		return cmp.Compare(a.hash, b.hash)
+	})
+
+	return c, nil
+}
+
+// NodeFor returns the index of the node that owns key.
+func (c *Cluster[T]) NodeFor(key string) int {
+	h := hash(key)
+	i, _ := slices.BinarySearchFunc(c.ring, h, func(p point, h uint64) int {
+		return cmp.Compare(p.hash, h)
+	})
+
+	return c.ring[i%len(c.ring)].node
+}
+
+func (c *Cluster[T]) Insert(value T, priority int) error {
+	return c.nodes[c.rotate()].Insert(value, priority)
+}
+
+// InsertKeyed inserts into the node owning key, replacing the element with
+// the same key there.
+func (c *Cluster[T]) InsertKeyed(key string, value T, priority int) error {
+	return c.nodes[c.NodeFor(key)].InsertKeyed(key, value, priority)
+}
+
+func (c *Cluster[T]) Dequeue() (prb.Element[T], error) {
+	node, _, err := c.best()
+	if err != nil {
+		return prb.Element[T]{}, err
+	}
+
+	return c.nodes[node].Dequeue()
+}
+
+func (c *Cluster[T]) Peek() (prb.Element[T], error) {
+	_, head, err := c.best()
+	return head, err
+}
+
+// best returns the node whose head has the highest priority and that head.
+// It returns
+// prb.ErrBufferEmpty if every node is empty and the first other error a
+// node reports.
+func (c *Cluster[T]) best() (int, prb.Element[T], error) {
+	start := c.rotate()
+	best := -1
+	var head prb.Element[T]
+
+	for i := range c.nodes {
+		node := (start + i) % len(c.nodes)
+		e, err := c.nodes[node].Peek()
+		if errors.Is(err, prb.ErrBufferEmpty) {
+			continue
+		}
+		if err != nil {
+			return -1, prb.Element[T]{}, err
+		}
+		if best < 0 || e.Priority > head.Priority {
+			best, head = node, e
+		}
+	}
+
+	if best < 0 {
+		return -1, prb.Element[T]{}, prb.ErrBufferEmpty
+	}
+
+	return best, head, nil
+}
+
+func (c *Cluster[T]) rotate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.next
+	c.next = (c.next + 1) % len(c.nodes)
+	return n
+}
+
+// hash places s on the ring. Every client must agree on it, so it is not
+// seeded, and short similar keys such as counters must still spread out.
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:])
+}
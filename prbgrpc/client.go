@@ -0,0 +1,116 @@
+package prbgrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"GoPRB/prb"
+	"GoPRB/prbgrpc/prbpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// remoteErrors are the buffer errors a Client recognises in status messages.
+var remoteErrors = []error{
+	prb.ErrBufferFull,
+	prb.ErrBufferEmpty,
+	prb.ErrRateLimited,
+	prb.ErrQuotaExceeded,
+	prb.ErrClosed,
+	prb.ErrMmapMetadata,
+}
+
+// Client is a buffer served by Server on another process. Errors returned
+// by the remote buffer match the prb sentinel errors with errors.Is.
+type Client[T comparable] struct {
+	client prbpb.PriorityBufferClient
+	codec  prb.Codec[T]
+}
+
+// NewClient calls the server on conn, encoding values with codec, or
+// prb.DefaultCodec if codec is nil.
+func NewClient[T comparable](conn grpc.ClientConnInterface, codec prb.Codec[T]) *Client[T] {
+	if codec == nil {
+		codec = prb.DefaultCodec[T]{}
+	}
+
+	return &Client[T]{client: prbpb.NewPriorityBufferClient(conn), codec: codec}
+}
+
+func (c *Client[T]) Insert(value T, priority int) error {
+	return c.insert(&prbpb.InsertRequest{Priority: int64(priority)}, value)
+}
+
+func (c *Client[T]) InsertKeyed(key string, value T, priority int) error {
+	return c.insert(&prbpb.InsertRequest{Priority: int64(priority), Key: key}, value)
+}
+
+func (c *Client[T]) InsertWithMeta(value T, priority int, tags map[string]string) error {
+	return c.insert(&prbpb.InsertRequest{Priority: int64(priority), Tags: tags}, value)
+}
+
+func (c *Client[T]) insert(req *prbpb.InsertRequest, value T) error {
+	var err error
+	if req.Value, err = c.codec.AppendValue(nil, value); err != nil {
+		return err
+	}
+
+	_, err = c.client.Insert(context.Background(), req)
+	return fromStatus(err)
+}
+
+func (c *Client[T]) Dequeue() (prb.Element[T], error) {
+	e, err := c.client.Dequeue(context.Background(), &prbpb.DequeueRequest{})
+	if err != nil {
+		return prb.Element[T]{}, fromStatus(err)
+	}
+
+	return c.element(e)
+}
+
+func (c *Client[T]) Peek() (prb.Element[T], error) {
+	e, err := c.client.Peek(context.Background(), &prbpb.PeekRequest{})
+	if err != nil {
+		return prb.Element[T]{}, fromStatus(err)
+	}
+
+	return c.element(e)
+}
+
+func (c *Client[T]) element(e *prbpb.Element) (prb.Element[T], error) {
+	value, err := c.codec.DecodeValue(e.GetValue())
+	if err != nil {
+		return prb.Element[T]{}, err
+	}
+
+	return prb.Element[T]{
+		Value:          value,
+		Priority:       int(e.GetPriority()),
+		InsertionOrder: e.GetInsertionOrder(),
+		GuaranteedMax:  e.GetGuaranteedMax(),
+		Key:            e.GetKey(),
+		Tags:           e.GetTags(),
+	}, nil
+}
+
+// fromStatus turns a status carrying a buffer error back into that error.
+func fromStatus(err error) error {
+	s, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+
+	message := s.Message()
+	for _, remote := range remoteErrors {
+		if message == remote.Error() {
+			return remote
+		}
+		if rest, ok := strings.CutPrefix(message, remote.Error()); ok {
+			return fmt.Errorf("%w%s", remote, rest)
+		}
+	}
+
+	return err
+}
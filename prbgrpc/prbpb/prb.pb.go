@@ -0,0 +1,767 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: prb.proto
+
+package prbpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Event_Kind int32
+
+const (
+	Event_KIND_UNSPECIFIED    Event_Kind = 0
+	Event_KIND_INSERT         Event_Kind = 1
+	Event_KIND_DEQUEUE        Event_Kind = 2
+	Event_KIND_EVICT          Event_Kind = 3
+	Event_KIND_REJECT         Event_Kind = 4
+	Event_KIND_CLEAR          Event_Kind = 5
+	Event_KIND_REMOVE         Event_Kind = 6
+	Event_KIND_HIGH_WATERMARK Event_Kind = 7
+	Event_KIND_LOW_WATERMARK  Event_Kind = 8
+)
+
+// Enum value maps for Event_Kind.
+var (
+	Event_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_INSERT",
+		2: "KIND_DEQUEUE",
+		3: "KIND_EVICT",
+		4: "KIND_REJECT",
+		5: "KIND_CLEAR",
+		6: "KIND_REMOVE",
+		7: "KIND_HIGH_WATERMARK",
+		8: "KIND_LOW_WATERMARK",
+	}
+	Event_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED":    0,
+		"KIND_INSERT":         1,
+		"KIND_DEQUEUE":        2,
+		"KIND_EVICT":          3,
+		"KIND_REJECT":         4,
+		"KIND_CLEAR":          5,
+		"KIND_REMOVE":         6,
+		"KIND_HIGH_WATERMARK": 7,
+		"KIND_LOW_WATERMARK":  8,
+	}
+)
+
+func (x Event_Kind) Enum() *Event_Kind {
+	p := new(Event_Kind)
+	*p = x
+	return p
+}
+
+func (x Event_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Event_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_prb_proto_enumTypes[0].Descriptor()
+}
+
+func (Event_Kind) Type() protoreflect.EnumType {
+	return &file_prb_proto_enumTypes[0]
+}
+
+func (x Event_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Event_Kind.Descriptor instead.
+func (Event_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type Element struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Value          []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Priority       int64                  `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	InsertionOrder int64                  `protobuf:"varint,3,opt,name=insertion_order,json=insertionOrder,proto3" json:"insertion_order,omitempty"`
+	GuaranteedMax  bool                   `protobuf:"varint,4,opt,name=guaranteed_max,json=guaranteedMax,proto3" json:"guaranteed_max,omitempty"`
+	Key            string                 `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	Tags           map[string]string      `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Element) Reset() {
+	*x = Element{}
+	mi := &file_prb_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Element) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Element) ProtoMessage() {}
+
+func (x *Element) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Element.ProtoReflect.Descriptor instead.
+func (*Element) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Element) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Element) GetPriority() int64 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Element) GetInsertionOrder() int64 {
+	if x != nil {
+		return x.InsertionOrder
+	}
+	return 0
+}
+
+func (x *Element) GetGuaranteedMax() bool {
+	if x != nil {
+		return x.GuaranteedMax
+	}
+	return false
+}
+
+func (x *Element) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Element) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type InsertRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Value    []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Priority int64                  `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	// key makes the insert replace the queued element with the same key.
+	Key           string            `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Tags          map[string]string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InsertRequest) Reset() {
+	*x = InsertRequest{}
+	mi := &file_prb_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InsertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsertRequest) ProtoMessage() {}
+
+func (x *InsertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsertRequest.ProtoReflect.Descriptor instead.
+func (*InsertRequest) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *InsertRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *InsertRequest) GetPriority() int64 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *InsertRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *InsertRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type InsertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InsertResponse) Reset() {
+	*x = InsertResponse{}
+	mi := &file_prb_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InsertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsertResponse) ProtoMessage() {}
+
+func (x *InsertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsertResponse.ProtoReflect.Descriptor instead.
+func (*InsertResponse) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{2}
+}
+
+type DequeueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DequeueRequest) Reset() {
+	*x = DequeueRequest{}
+	mi := &file_prb_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DequeueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DequeueRequest) ProtoMessage() {}
+
+func (x *DequeueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DequeueRequest.ProtoReflect.Descriptor instead.
+func (*DequeueRequest) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{3}
+}
+
+type PeekRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeekRequest) Reset() {
+	*x = PeekRequest{}
+	mi := &file_prb_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeekRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeekRequest) ProtoMessage() {}
+
+func (x *PeekRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeekRequest.ProtoReflect.Descriptor instead.
+func (*PeekRequest) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{4}
+}
+
+type SearchRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Priority    *int64                 `protobuf:"varint,1,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	MinPriority *int64                 `protobuf:"varint,2,opt,name=min_priority,json=minPriority,proto3,oneof" json:"min_priority,omitempty"`
+	Key         string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	// tags must all be present with the given values.
+	Tags map[string]string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// limit caps the number of results; zero means no limit.
+	Limit         int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_prb_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchRequest) GetPriority() int64 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetMinPriority() int64 {
+	if x != nil && x.MinPriority != nil {
+		return *x.MinPriority
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Element       *Element               `protobuf:"bytes,2,opt,name=element,proto3" json:"element,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_prb_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SearchResult) GetElement() *Element {
+	if x != nil {
+		return x.Element
+	}
+	return nil
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_prb_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_prb_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{8}
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          Event_Kind             `protobuf:"varint,1,opt,name=kind,proto3,enum=prb.v1.Event_Kind" json:"kind,omitempty"`
+	Element       *Element               `protobuf:"bytes,2,opt,name=element,proto3" json:"element,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_prb_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_prb_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_prb_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Event) GetKind() Event_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Event_KIND_UNSPECIFIED
+}
+
+func (x *Event) GetElement() *Element {
+	if x != nil {
+		return x.Element
+	}
+	return nil
+}
+
+func (x *Event) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_prb_proto protoreflect.FileDescriptor
+
+const file_prb_proto_rawDesc = "" +
+	"\n" +
+	"\tprb.proto\x12\x06prb.v1\"\x85\x02\n" +
+	"\aElement\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\x03R\bpriority\x12'\n" +
+	"\x0finsertion_order\x18\x03 \x01(\x03R\x0einsertionOrder\x12%\n" +
+	"\x0eguaranteed_max\x18\x04 \x01(\bR\rguaranteedMax\x12\x10\n" +
+	"\x03key\x18\x05 \x01(\tR\x03key\x12-\n" +
+	"\x04tags\x18\x06 \x03(\v2\x19.prb.v1.Element.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc1\x01\n" +
+	"\rInsertRequest\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\x03R\bpriority\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x123\n" +
+	"\x04tags\x18\x04 \x03(\v2\x1f.prb.v1.InsertRequest.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x10\n" +
+	"\x0eInsertResponse\"\x10\n" +
+	"\x0eDequeueRequest\"\r\n" +
+	"\vPeekRequest\"\x8c\x02\n" +
+	"\rSearchRequest\x12\x1f\n" +
+	"\bpriority\x18\x01 \x01(\x03H\x00R\bpriority\x88\x01\x01\x12&\n" +
+	"\fmin_priority\x18\x02 \x01(\x03H\x01R\vminPriority\x88\x01\x01\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x123\n" +
+	"\x04tags\x18\x04 \x03(\v2\x1f.prb.v1.SearchRequest.TagsEntryR\x04tags\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\t_priorityB\x0f\n" +
+	"\r_min_priority\"O\n" +
+	"\fSearchResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12)\n" +
+	"\aelement\x18\x02 \x01(\v2\x0f.prb.v1.ElementR\aelement\"@\n" +
+	"\x0eSearchResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.prb.v1.SearchResultR\aresults\"\x0e\n" +
+	"\fWatchRequest\"\xa5\x02\n" +
+	"\x05Event\x12&\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x12.prb.v1.Event.KindR\x04kind\x12)\n" +
+	"\aelement\x18\x02 \x01(\v2\x0f.prb.v1.ElementR\aelement\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xb2\x01\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vKIND_INSERT\x10\x01\x12\x10\n" +
+	"\fKIND_DEQUEUE\x10\x02\x12\x0e\n" +
+	"\n" +
+	"KIND_EVICT\x10\x03\x12\x0f\n" +
+	"\vKIND_REJECT\x10\x04\x12\x0e\n" +
+	"\n" +
+	"KIND_CLEAR\x10\x05\x12\x0f\n" +
+	"\vKIND_REMOVE\x10\x06\x12\x17\n" +
+	"\x13KIND_HIGH_WATERMARK\x10\a\x12\x16\n" +
+	"\x12KIND_LOW_WATERMARK\x10\b2\x94\x02\n" +
+	"\x0ePriorityBuffer\x127\n" +
+	"\x06Insert\x12\x15.prb.v1.InsertRequest\x1a\x16.prb.v1.InsertResponse\x122\n" +
+	"\aDequeue\x12\x16.prb.v1.DequeueRequest\x1a\x0f.prb.v1.Element\x12,\n" +
+	"\x04Peek\x12\x13.prb.v1.PeekRequest\x1a\x0f.prb.v1.Element\x127\n" +
+	"\x06Search\x12\x15.prb.v1.SearchRequest\x1a\x16.prb.v1.SearchResponse\x12.\n" +
+	"\x05Watch\x12\x14.prb.v1.WatchRequest\x1a\r.prb.v1.Event0\x01B\x15Z\x13GoPRB/prbgrpc/prbpbb\x06proto3"
+
+var (
+	file_prb_proto_rawDescOnce sync.Once
+	file_prb_proto_rawDescData []byte
+)
+
+func file_prb_proto_rawDescGZIP() []byte {
+	file_prb_proto_rawDescOnce.Do(func() {
+		file_prb_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_prb_proto_rawDesc), len(file_prb_proto_rawDesc)))
+	})
+	return file_prb_proto_rawDescData
+}
+
+var file_prb_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_prb_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_prb_proto_goTypes = []any{
+	(Event_Kind)(0),        // 0: prb.v1.Event.Kind
+	(*Element)(nil),        // 1: prb.v1.Element
+	(*InsertRequest)(nil),  // 2: prb.v1.InsertRequest
+	(*InsertResponse)(nil), // 3: prb.v1.InsertResponse
+	(*DequeueRequest)(nil), // 4: prb.v1.DequeueRequest
+	(*PeekRequest)(nil),    // 5: prb.v1.PeekRequest
+	(*SearchRequest)(nil),  // 6: prb.v1.SearchRequest
+	(*SearchResult)(nil),   // 7: prb.v1.SearchResult
+	(*SearchResponse)(nil), // 8: prb.v1.SearchResponse
+	(*WatchRequest)(nil),   // 9: prb.v1.WatchRequest
+	(*Event)(nil),          // 10: prb.v1.Event
+	nil,                    // 11: prb.v1.Element.TagsEntry
+	nil,                    // 12: prb.v1.InsertRequest.TagsEntry
+	nil,                    // 13: prb.v1.SearchRequest.TagsEntry
+}
+var file_prb_proto_depIdxs = []int32{
+	11, // 0: prb.v1.Element.tags:type_name -> prb.v1.Element.TagsEntry
+	12, // 1: prb.v1.InsertRequest.tags:type_name -> prb.v1.InsertRequest.TagsEntry
+	13, // 2: prb.v1.SearchRequest.tags:type_name -> prb.v1.SearchRequest.TagsEntry
+	1,  // 3: prb.v1.SearchResult.element:type_name -> prb.v1.Element
+	7,  // 4: prb.v1.SearchResponse.results:type_name -> prb.v1.SearchResult
+	0,  // 5: prb.v1.Event.kind:type_name -> prb.v1.Event.Kind
+	1,  // 6: prb.v1.Event.element:type_name -> prb.v1.Element
+	2,  // 7: prb.v1.PriorityBuffer.Insert:input_type -> prb.v1.InsertRequest
+	4,  // 8: prb.v1.PriorityBuffer.Dequeue:input_type -> prb.v1.DequeueRequest
+	5,  // 9: prb.v1.PriorityBuffer.Peek:input_type -> prb.v1.PeekRequest
+	6,  // 10: prb.v1.PriorityBuffer.Search:input_type -> prb.v1.SearchRequest
+	9,  // 11: prb.v1.PriorityBuffer.Watch:input_type -> prb.v1.WatchRequest
+	3,  // 12: prb.v1.PriorityBuffer.Insert:output_type -> prb.v1.InsertResponse
+	1,  // 13: prb.v1.PriorityBuffer.Dequeue:output_type -> prb.v1.Element
+	1,  // 14: prb.v1.PriorityBuffer.Peek:output_type -> prb.v1.Element
+	8,  // 15: prb.v1.PriorityBuffer.Search:output_type -> prb.v1.SearchResponse
+	10, // 16: prb.v1.PriorityBuffer.Watch:output_type -> prb.v1.Event
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_prb_proto_init() }
+func file_prb_proto_init() {
+	if File_prb_proto != nil {
+		return
+	}
+	file_prb_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_prb_proto_rawDesc), len(file_prb_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_prb_proto_goTypes,
+		DependencyIndexes: file_prb_proto_depIdxs,
+		EnumInfos:         file_prb_proto_enumTypes,
+		MessageInfos:      file_prb_proto_msgTypes,
+	}.Build()
+	File_prb_proto = out.File
+	file_prb_proto_goTypes = nil
+	file_prb_proto_depIdxs = nil
+}
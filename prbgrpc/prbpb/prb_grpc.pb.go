@@ -0,0 +1,285 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: prb.proto
+
+package prbpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PriorityBuffer_Insert_FullMethodName  = "/prb.v1.PriorityBuffer/Insert"
+	PriorityBuffer_Dequeue_FullMethodName = "/prb.v1.PriorityBuffer/Dequeue"
+	PriorityBuffer_Peek_FullMethodName    = "/prb.v1.PriorityBuffer/Peek"
+	PriorityBuffer_Search_FullMethodName  = "/prb.v1.PriorityBuffer/Search"
+	PriorityBuffer_Watch_FullMethodName   = "/prb.v1.PriorityBuffer/Watch"
+)
+
+// PriorityBufferClient is the client API for PriorityBuffer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PriorityBuffer serves one priority ring buffer. Values are opaque bytes
+// encoded with the server's value codec.
+type PriorityBufferClient interface {
+	Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertResponse, error)
+	Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*Element, error)
+	Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*Element, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Watch streams buffer events until the client cancels.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type priorityBufferClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPriorityBufferClient(cc grpc.ClientConnInterface) PriorityBufferClient {
+	return &priorityBufferClient{cc}
+}
+
+func (c *priorityBufferClient) Insert(ctx context.Context, in *InsertRequest, opts ...grpc.CallOption) (*InsertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InsertResponse)
+	err := c.cc.Invoke(ctx, PriorityBuffer_Insert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priorityBufferClient) Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*Element, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Element)
+	err := c.cc.Invoke(ctx, PriorityBuffer_Dequeue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priorityBufferClient) Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*Element, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Element)
+	err := c.cc.Invoke(ctx, PriorityBuffer_Peek_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priorityBufferClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, PriorityBuffer_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priorityBufferClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PriorityBuffer_ServiceDesc.Streams[0], PriorityBuffer_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PriorityBuffer_WatchClient = grpc.ServerStreamingClient[Event]
+
+// PriorityBufferServer is the server API for PriorityBuffer service.
+// All implementations must embed UnimplementedPriorityBufferServer
+// for forward compatibility.
+//
+// PriorityBuffer serves one priority ring buffer. Values are opaque bytes
+// encoded with the server's value codec.
+type PriorityBufferServer interface {
+	Insert(context.Context, *InsertRequest) (*InsertResponse, error)
+	Dequeue(context.Context, *DequeueRequest) (*Element, error)
+	Peek(context.Context, *PeekRequest) (*Element, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Watch streams buffer events until the client cancels.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedPriorityBufferServer()
+}
+
+// UnimplementedPriorityBufferServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPriorityBufferServer struct{}
+
+func (UnimplementedPriorityBufferServer) Insert(context.Context, *InsertRequest) (*InsertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Insert not implemented")
+}
+func (UnimplementedPriorityBufferServer) Dequeue(context.Context, *DequeueRequest) (*Element, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Dequeue not implemented")
+}
+func (UnimplementedPriorityBufferServer) Peek(context.Context, *PeekRequest) (*Element, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Peek not implemented")
+}
+func (UnimplementedPriorityBufferServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedPriorityBufferServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedPriorityBufferServer) mustEmbedUnimplementedPriorityBufferServer() {}
+func (UnimplementedPriorityBufferServer) testEmbeddedByValue()                        {}
+
+// UnsafePriorityBufferServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PriorityBufferServer will
+// result in compilation errors.
+type UnsafePriorityBufferServer interface {
+	mustEmbedUnimplementedPriorityBufferServer()
+}
+
+func RegisterPriorityBufferServer(s grpc.ServiceRegistrar, srv PriorityBufferServer) {
+	// If the following call pancis, it indicates UnimplementedPriorityBufferServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PriorityBuffer_ServiceDesc, srv)
+}
+
+func _PriorityBuffer_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriorityBufferServer).Insert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriorityBuffer_Insert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriorityBufferServer).Insert(ctx, req.(*InsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriorityBuffer_Dequeue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DequeueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriorityBufferServer).Dequeue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriorityBuffer_Dequeue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriorityBufferServer).Dequeue(ctx, req.(*DequeueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriorityBuffer_Peek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeekRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriorityBufferServer).Peek(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriorityBuffer_Peek_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriorityBufferServer).Peek(ctx, req.(*PeekRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriorityBuffer_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriorityBufferServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriorityBuffer_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriorityBufferServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriorityBuffer_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PriorityBufferServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PriorityBuffer_WatchServer = grpc.ServerStreamingServer[Event]
+
+// PriorityBuffer_ServiceDesc is the grpc.ServiceDesc for PriorityBuffer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PriorityBuffer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "prb.v1.PriorityBuffer",
+	HandlerType: (*PriorityBufferServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Insert",
+			Handler:    _PriorityBuffer_Insert_Handler,
+		},
+		{
+			MethodName: "Dequeue",
+			Handler:    _PriorityBuffer_Dequeue_Handler,
+		},
+		{
+			MethodName: "Peek",
+			Handler:    _PriorityBuffer_Peek_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _PriorityBuffer_Search_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _PriorityBuffer_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "prb.proto",
+}
@@ -0,0 +1,245 @@
+// Package prbgrpc serves a buffer over gRPC with the PriorityBuffer service
+// defined in prbpb/prb.proto.
+package prbgrpc
+
+//go:generate protoc -I prbpb --go_out=prbpb --go_opt=paths=source_relative --go-grpc_out=prbpb --go-grpc_opt=paths=source_relative prb.proto
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"sync"
+
+	"GoPRB/prb"
+	"GoPRB/prbgrpc/prbpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchBuffer is how many events a slow Watch stream may fall behind before
+// further events are dropped for it.
+const watchBuffer = 64
+
+var eventKinds = map[prb.EventKind]prbpb.Event_Kind{
+	prb.EventInsert:        prbpb.Event_KIND_INSERT,
+	prb.EventDequeue:       prbpb.Event_KIND_DEQUEUE,
+	prb.EventEvict:         prbpb.Event_KIND_EVICT,
+	prb.EventReject:        prbpb.Event_KIND_REJECT,
+	prb.EventClear:         prbpb.Event_KIND_CLEAR,
+	prb.EventRemove:        prbpb.Event_KIND_REMOVE,
+	prb.EventHighWatermark: prbpb.Event_KIND_HIGH_WATERMARK,
+	prb.EventLowWatermark:  prbpb.Event_KIND_LOW_WATERMARK,
+}
+
+// Server implements prbpb.PriorityBufferServer over a buffer. Values cross
+// the wire encoded with a prb.Codec. Watch consumes the buffer's event
+// stream, so nothing else should read from Events once a client watches.
+type Server[T comparable] struct {
+	prbpb.UnimplementedPriorityBufferServer
+
+	buffer *prb.PriorityRingBuffer[T]
+	codec  prb.Codec[T]
+
+	mu       sync.Mutex
+	watching bool
+	watchers map[chan *prbpb.Event]struct{}
+}
+
+// NewServer serves b, encoding values with codec, or prb.DefaultCodec if
+// codec is nil. Register it with prbpb.RegisterPriorityBufferServer.
+func NewServer[T comparable](b *prb.PriorityRingBuffer[T], codec prb.Codec[T]) *Server[T] {
+	if codec == nil {
+		codec = prb.DefaultCodec[T]{}
+	}
+
+	return &Server[T]{
+		buffer:   b,
+		codec:    codec,
+		watchers: make(map[chan *prbpb.Event]struct{}),
+	}
+}
+
+func (s *Server[T]) Insert(ctx context.Context, req *prbpb.InsertRequest) (*prbpb.InsertResponse, error) {
+	value, err := s.codec.DecodeValue(req.GetValue())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	priority := int(req.GetPriority())
+	switch {
+	case req.GetKey() != "" && len(req.GetTags()) > 0:
+		return nil, status.Error(codes.InvalidArgument, "key and tags cannot be combined")
+	case req.GetKey() != "":
+		err = s.buffer.InsertKeyed(req.GetKey(), value, priority)
+	default:
+		err = s.buffer.InsertWithMeta(value, priority, req.GetTags())
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &prbpb.InsertResponse{}, nil
+}
+
+func (s *Server[T]) Dequeue(ctx context.Context, req *prbpb.DequeueRequest) (*prbpb.Element, error) {
+	element, err := s.buffer.Dequeue()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.element(element)
+}
+
+func (s *Server[T]) Peek(ctx context.Context, req *prbpb.PeekRequest) (*prbpb.Element, error) {
+	element, err := s.buffer.Peek()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.element(element)
+}
+
+func (s *Server[T]) Search(ctx context.Context, req *prbpb.SearchRequest) (*prbpb.SearchResponse, error) {
+	var filters []prb.SearchFilter[T]
+	if req.Priority != nil {
+		filters = append(filters, prb.SearchByPriority[T](int(req.GetPriority())))
+	}
+	if req.MinPriority != nil {
+		filters = append(filters, prb.SearchByMinPriority[T](int(req.GetMinPriority())))
+	}
+	if key := req.GetKey(); key != "" {
+		filters = append(filters, func(e prb.Element[T]) bool { return e.Key == key })
+	}
+	for name, value := range req.GetTags() {
+		filters = append(filters, prb.SearchByTag[T](name, value))
+	}
+
+	var matches []prb.Element[T]
+	var indexes []int
+	s.buffer.SearchFunc(func(i int, e prb.Element[T]) bool {
+		matches = append(matches, e)
+		indexes = append(indexes, i)
+		return req.GetLimit() <= 0 || len(matches) < int(req.GetLimit())
+	}, filters...)
+
+	response := &prbpb.SearchResponse{}
+	for i, e := range matches {
+		element, err := s.element(e)
+		if err != nil {
+			return nil, err
+		}
+		response.Results = append(response.Results, &prbpb.SearchResult{
+			Index:   int32(indexes[i]),
+			Element: element,
+		})
+	}
+
+	return response, nil
+}
+
+// Watch streams buffer events until the client cancels or the buffer is
+// closed. Events are dropped for streams that fall too far behind.
+func (s *Server[T]) Watch(req *prbpb.WatchRequest, stream grpc.ServerStreamingServer[prbpb.Event]) error {
+	events := make(chan *prbpb.Event, watchBuffer)
+
+	s.mu.Lock()
+	if !s.watching {
+		s.watching = true
+		events := s.buffer.Events()
+		go pprof.Do(context.Background(), pprof.Labels("prb", "grpc-watch"), func(context.Context) {
+			s.broadcast(events)
+		})
+	}
+	s.watchers[events] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, events)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// broadcast fans the buffer's events out to every watcher and closes their
+// channels once the buffer closes its stream.
+func (s *Server[T]) broadcast(events <-chan prb.Event[T]) {
+	for event := range events {
+		message := &prbpb.Event{Kind: eventKinds[event.Kind]}
+		if event.Kind != prb.EventHighWatermark && event.Kind != prb.EventLowWatermark && event.Kind != prb.EventClear {
+			element, err := s.element(event.Element)
+			if err != nil {
+				continue
+			}
+			message.Element = element
+		}
+		if event.Err != nil {
+			message.Error = event.Err.Error()
+		}
+
+		s.mu.Lock()
+		for w := range s.watchers {
+			select {
+			case w <- message:
+			default:
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	for w := range s.watchers {
+		close(w)
+		delete(s.watchers, w)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Server[T]) element(e prb.Element[T]) (*prbpb.Element, error) {
+	value, err := s.codec.AppendValue(nil, e.Value)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &prbpb.Element{
+		Value:          value,
+		Priority:       int64(e.Priority),
+		InsertionOrder: e.InsertionOrder,
+		GuaranteedMax:  e.GuaranteedMax,
+		Key:            e.Key,
+		Tags:           e.Tags,
+	}, nil
+}
+
+func toStatus(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, prb.ErrBufferFull), errors.Is(err, prb.ErrRateLimited):
+		code = codes.ResourceExhausted
+	case errors.Is(err, prb.ErrBufferEmpty):
+		code = codes.NotFound
+	case errors.Is(err, prb.ErrQuotaExceeded):
+		code = codes.FailedPrecondition
+	case errors.Is(err, prb.ErrClosed):
+		code = codes.Unavailable
+	case errors.Is(err, prb.ErrMmapMetadata):
+		code = codes.InvalidArgument
+	}
+
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,61 @@
+// Package prbhttp exposes buffers over HTTP.
+package prbhttp
+
+import (
+	"net/http"
+	"strconv"
+
+	"GoPRB/prb"
+)
+
+type debugConfig struct {
+	BubbleWindow int              `json:"bubbleWindow"`
+	OverflowMode prb.OverflowMode `json:"overflowMode"`
+	Quotas       []prb.Quota      `json:"quotas,omitempty"`
+}
+
+type debugResponse[T comparable] struct {
+	Size     int              `json:"size"`
+	Capacity int              `json:"capacity"`
+	Config   debugConfig      `json:"config"`
+	Stats    prb.Stats        `json:"stats"`
+	Elements []prb.Element[T] `json:"elements,omitempty"`
+}
+
+// DebugHandler serves a JSON summary of b: size, capacity, configuration,
+// stats and the first elements elements in dequeue order. A request can ask
+// for a different number of elements with the elements query parameter, as
+// in /debug/prb?elements=20.
+func DebugHandler[T comparable](b *prb.PriorityRingBuffer[T], elements int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := elements
+		if q := r.URL.Query().Get("elements"); q != "" {
+			var err error
+			if n, err = strconv.Atoi(q); err != nil || n < 0 {
+				http.Error(w, "elements must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		stats := b.GetStats()
+		response := debugResponse[T]{
+			Size:     stats.Size,
+			Capacity: stats.Capacity,
+			Config: debugConfig{
+				BubbleWindow: stats.BubbleWindow,
+				OverflowMode: b.OverflowMode(),
+				Quotas:       b.Quotas(),
+			},
+			Stats: stats,
+		}
+
+		if n > 0 {
+			b.ForEach(func(i int, e prb.Element[T]) bool {
+				response.Elements = append(response.Elements, e)
+				return i+1 < n
+			})
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	})
+}
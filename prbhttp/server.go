@@ -0,0 +1,188 @@
+package prbhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"GoPRB/prb"
+)
+
+// Server exposes a buffer as a JSON API:
+//
+//	POST /insert   {"value": ..., "priority": 3, "key": "...", "tags": {...}}
+//	POST /dequeue  returns the head element
+//	GET  /peek     returns the head element without removing it
+//	GET  /search   returns matching elements, filtered by the priority,
+//	               minPriority, value, key and tag (name:value) parameters
+//	GET  /stats    returns prb.Stats
+//
+// A full buffer answers 503, an empty one 404, a rate limited insert 429, a
+// quota violation 409 and a closed buffer 410.
+type Server[T comparable] struct {
+	buffer *prb.PriorityRingBuffer[T]
+	mux    *http.ServeMux
+}
+
+type insertRequest[T comparable] struct {
+	Value    T                 `json:"value"`
+	Priority int               `json:"priority"`
+	Key      string            `json:"key,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+type searchResult[T comparable] struct {
+	Index   int            `json:"index"`
+	Element prb.Element[T] `json:"element"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewServer[T comparable](b *prb.PriorityRingBuffer[T]) *Server[T] {
+	s := &Server[T]{buffer: b, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /insert", s.insert)
+	s.mux.HandleFunc("POST /dequeue", s.dequeue)
+	s.mux.HandleFunc("GET /peek", s.peek)
+	s.mux.HandleFunc("GET /search", s.search)
+	s.mux.HandleFunc("GET /stats", s.stats)
+	return s
+}
+
+func (s *Server[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server[T]) insert(w http.ResponseWriter, r *http.Request) {
+	var req insertRequest[T]
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	var err error
+	switch {
+	case req.Key != "" && len(req.Tags) > 0:
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "key and tags cannot be combined"})
+		return
+	case req.Key != "":
+		err = s.buffer.InsertKeyed(req.Key, req.Value, req.Priority)
+	default:
+		err = s.buffer.InsertWithMeta(req.Value, req.Priority, req.Tags)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server[T]) dequeue(w http.ResponseWriter, r *http.Request) {
+	element, err := s.buffer.Dequeue()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, element)
+}
+
+func (s *Server[T]) peek(w http.ResponseWriter, r *http.Request) {
+	element, err := s.buffer.Peek()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, element)
+}
+
+func (s *Server[T]) search(w http.ResponseWriter, r *http.Request) {
+	filters, err := searchFilters[T](r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	results := []searchResult[T]{}
+	s.buffer.SearchFunc(func(i int, e prb.Element[T]) bool {
+		results = append(results, searchResult[T]{Index: i, Element: e})
+		return true
+	}, filters...)
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server[T]) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.buffer.GetStats())
+}
+
+func searchFilters[T comparable](r *http.Request) ([]prb.SearchFilter[T], error) {
+	query := r.URL.Query()
+	var filters []prb.SearchFilter[T]
+
+	for name, filter := range map[string]func(int) prb.SearchFilter[T]{
+		"priority":    prb.SearchByPriority[T],
+		"minPriority": prb.SearchByMinPriority[T],
+	} {
+		if q := query.Get(name); q != "" {
+			n, err := strconv.Atoi(q)
+			if err != nil {
+				return nil, errors.New(name + " must be an integer")
+			}
+			filters = append(filters, filter(n))
+		}
+	}
+
+	if q := query.Get("value"); q != "" {
+		var value T
+		if err := json.Unmarshal([]byte(q), &value); err != nil {
+			return nil, errors.New("value must be JSON encoded")
+		}
+		filters = append(filters, prb.SearchByValue(value))
+	}
+
+	if q := query.Get("key"); q != "" {
+		filters = append(filters, func(e prb.Element[T]) bool { return e.Key == q })
+	}
+
+	for _, q := range query["tag"] {
+		name, value, ok := strings.Cut(q, ":")
+		if !ok {
+			return nil, errors.New("tag must have the form name:value")
+		}
+		filters = append(filters, prb.SearchByTag[T](name, value))
+	}
+
+	return filters, nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, prb.ErrBufferFull):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, prb.ErrBufferEmpty):
+		status = http.StatusNotFound
+	case errors.Is(err, prb.ErrRateLimited):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, prb.ErrQuotaExceeded):
+		status = http.StatusConflict
+	case errors.Is(err, prb.ErrClosed):
+		status = http.StatusGone
+	case errors.Is(err, prb.ErrMmapMetadata):
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,74 @@
+// Package prbio adapts buffers to the io interfaces.
+package prbio
+
+import (
+	"bytes"
+	"sync"
+
+	"GoPRB/prb"
+)
+
+// Writer inserts every line written to it into a buffer of strings.
+type Writer struct {
+	buffer     *prb.PriorityRingBuffer[string]
+	priorityOf func([]byte) int
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+// NewWriter returns a writer that splits written data on newlines and
+// inserts each line, without its line ending, into b with the priority
+// priorityOf returns for it. A line is inserted once its newline arrives;
+// Close inserts a final unterminated line.
+func NewWriter(b *prb.PriorityRingBuffer[string], priorityOf func(line []byte) int) *Writer {
+	return &Writer{buffer: b, priorityOf: priorityOf}
+}
+
+// Write inserts every complete line in p. If an insert fails, Write returns
+// the error and the number of bytes before the failed line, which is
+// dropped.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	written := 0
+	for {
+		i := bytes.IndexByte(p[written:], '\n')
+		if i < 0 {
+			w.partial = append(w.partial, p[written:]...)
+			return len(p), nil
+		}
+
+		line := p[written : written+i]
+		if len(w.partial) > 0 {
+			line = append(w.partial, line...)
+			w.partial = w.partial[:0]
+		}
+
+		if err := w.insert(line); err != nil {
+			return written, err
+		}
+		written += i + 1
+	}
+}
+
+// Close inserts the data written since the last newline, if any. It does
+// not close the buffer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) == 0 {
+		return nil
+	}
+
+	line := w.partial
+	w.partial = nil
+	return w.insert(line)
+}
+
+func (w *Writer) insert(line []byte) error {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return w.buffer.Insert(string(line), w.priorityOf(line))
+}
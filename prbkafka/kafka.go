@@ -0,0 +1,143 @@
+// Package prbkafka moves messages between Kafka topics and buffers.
+//
+// The package does not depend on a Kafka client. Consumer and Producer are
+// small enough to wrap any client: with segmentio/kafka-go, for example,
+// Fetch and Commit map to Reader.FetchMessage and Reader.CommitMessages, and
+// Produce to Writer.WriteMessages.
+package prbkafka
+
+import (
+	"context"
+	"errors"
+
+	"GoPRB/prb"
+)
+
+// Header is a Kafka record header.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a Kafka record.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+}
+
+// Header returns the value of the first header called key and whether there
+// is one.
+func (m Message) Header(key string) ([]byte, bool) {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return h.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Consumer reads a topic as part of a consumer group. Commit marks m and
+// every earlier message of its partition as processed.
+type Consumer interface {
+	Fetch(ctx context.Context) (Message, error)
+	Commit(ctx context.Context, m Message) error
+}
+
+// Producer writes a message and returns once it is acknowledged.
+type Producer interface {
+	Produce(ctx context.Context, m Message) error
+}
+
+// Source consumes a topic into a buffer.
+type Source[T comparable] struct {
+	buffer   *prb.PriorityRingBuffer[T]
+	consumer Consumer
+	decode   func(Message) (T, int, error)
+}
+
+// NewSource inserts every message consumer fetches into b with the value
+// and priority decode returns, typically reading the priority from a
+// header.
+func NewSource[T comparable](b *prb.PriorityRingBuffer[T], consumer Consumer, decode func(m Message) (value T, priority int, err error)) *Source[T] {
+	return &Source[T]{buffer: b, consumer: consumer, decode: decode}
+}
+
+// Run consumes until ctx is done or an error occurs. An offset is committed
+// only after its message is in the buffer, waiting for room when the buffer
+// is full, so a crash redelivers rather than loses messages. A message
+// decode rejects stops Run without being committed. Run returns nil when
+// ctx is done.
+func (s *Source[T]) Run(ctx context.Context) error {
+	for {
+		m, err := s.consumer.Fetch(ctx)
+		if err != nil {
+			return ignoreDone(ctx, err)
+		}
+
+		value, priority, err := s.decode(m)
+		if err != nil {
+			return err
+		}
+
+		if err := s.buffer.InsertContext(ctx, value, priority); err != nil {
+			return ignoreDone(ctx, err)
+		}
+
+		if err := s.consumer.Commit(ctx, m); err != nil {
+			return ignoreDone(ctx, err)
+		}
+	}
+}
+
+// Sink drains a buffer into a topic.
+type Sink[T comparable] struct {
+	buffer   *prb.PriorityRingBuffer[T]
+	producer Producer
+	encode   func(prb.Element[T]) (Message, error)
+}
+
+// NewSink produces every element dequeued from b as the message encode
+// returns for it.
+func NewSink[T comparable](b *prb.PriorityRingBuffer[T], producer Producer, encode func(e prb.Element[T]) (Message, error)) *Sink[T] {
+	return &Sink[T]{buffer: b, producer: producer, encode: encode}
+}
+
+// Run drains the buffer until ctx is done or an error occurs, waiting for
+// elements while it is empty. Elements are taken with DequeueDelivery and
+// acknowledged only once produced; an element that fails to encode or
+// produce is put back with Nack and its error returned. Run returns nil when
+// ctx is done or the buffer is closed.
+func (s *Sink[T]) Run(ctx context.Context) error {
+	for {
+		d, err := s.buffer.DequeueDeliveryContext(ctx)
+		if err != nil {
+			return ignoreDone(ctx, err)
+		}
+
+		m, err := s.encode(d.Element)
+		if err == nil {
+			err = s.producer.Produce(ctx, m)
+		}
+		if err != nil {
+			s.buffer.Nack(d.ID, 0)
+			return ignoreDone(ctx, err)
+		}
+
+		// Ack only fails if the ack timeout already put the element back,
+		// in which case it is produced again: delivery is at least once.
+		s.buffer.Ack(d.ID)
+	}
+}
+
+func ignoreDone(ctx context.Context, err error) error {
+	if ctx.Err() != nil || errors.Is(err, prb.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,169 @@
+// Package prbnats reorders NATS messages by priority: it subscribes a
+// subject into a buffer and publishes dequeued elements to another subject.
+package prbnats
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"GoPRB/prb"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DefaultPriorityHeader is the header priorities are read from and written
+// to unless WithPriorityHeader says otherwise.
+const DefaultPriorityHeader = "Priority"
+
+// Bridge moves messages between NATS subjects and a buffer. Values are
+// message payloads encoded with a prb.Codec.
+type Bridge[T comparable] struct {
+	conn     *nats.Conn
+	buffer   *prb.PriorityRingBuffer[T]
+	codec    prb.Codec[T]
+	header   string
+	priority func(*nats.Msg) (int, error)
+	publish  func(*nats.Msg) error
+	onError  func(*nats.Msg, error)
+}
+
+type Option[T comparable] func(*Bridge[T])
+
+func WithCodec[T comparable](codec prb.Codec[T]) Option[T] {
+	return func(br *Bridge[T]) {
+		br.codec = codec
+	}
+}
+
+// WithPriorityHeader reads and writes priorities as decimal integers in the
+// header name. Messages without it get priority zero.
+func WithPriorityHeader[T comparable](name string) Option[T] {
+	return func(br *Bridge[T]) {
+		br.header = name
+	}
+}
+
+// WithPriorityFunc derives an incoming message's priority with fn instead
+// of reading the priority header.
+func WithPriorityFunc[T comparable](fn func(*nats.Msg) (int, error)) Option[T] {
+	return func(br *Bridge[T]) {
+		br.priority = fn
+	}
+}
+
+// WithPublisher publishes outgoing messages with fn instead of the core
+// connection, for example through JetStream to wait for the stream's
+// acknowledgement.
+func WithPublisher[T comparable](fn func(*nats.Msg) error) Option[T] {
+	return func(br *Bridge[T]) {
+		br.publish = fn
+	}
+}
+
+// OnError is called with every incoming message that could not be decoded
+// or inserted.
+func OnError[T comparable](fn func(*nats.Msg, error)) Option[T] {
+	return func(br *Bridge[T]) {
+		br.onError = fn
+	}
+}
+
+func NewBridge[T comparable](nc *nats.Conn, b *prb.PriorityRingBuffer[T], opts ...Option[T]) *Bridge[T] {
+	br := &Bridge[T]{
+		conn:    nc,
+		buffer:  b,
+		codec:   prb.DefaultCodec[T]{},
+		header:  DefaultPriorityHeader,
+		publish: nc.PublishMsg,
+	}
+
+	for _, opt := range opts {
+		opt(br)
+	}
+
+	if br.priority == nil {
+		br.priority = br.headerPriority
+	}
+
+	return br
+}
+
+// Subscribe inserts every message published on subject into the buffer.
+func (br *Bridge[T]) Subscribe(subject string) (*nats.Subscription, error) {
+	return br.conn.Subscribe(subject, br.Handle)
+}
+
+// Handle inserts m into the buffer. It can be passed to JetStream
+// subscriptions, whose messages are acknowledged once inserted and
+// negatively acknowledged for redelivery if the insert fails.
+func (br *Bridge[T]) Handle(m *nats.Msg) {
+	_, err := m.Metadata()
+	jetStream := err == nil
+
+	err = br.insert(m)
+	if err != nil && br.onError != nil {
+		br.onError(m, err)
+	}
+
+	if jetStream {
+		if err != nil {
+			m.Nak()
+		} else {
+			m.Ack()
+		}
+	}
+}
+
+func (br *Bridge[T]) insert(m *nats.Msg) error {
+	value, err := br.codec.DecodeValue(m.Data)
+	if err != nil {
+		return err
+	}
+
+	priority, err := br.priority(m)
+	if err != nil {
+		return err
+	}
+
+	return br.buffer.Insert(value, priority)
+}
+
+func (br *Bridge[T]) headerPriority(m *nats.Msg) (int, error) {
+	value := m.Header.Get(br.header)
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+// Publish publishes dequeued elements to subject, highest priority first,
+// with their priority in the priority header, until ctx is done or
+// publishing fails. Elements are acknowledged once published; one that
+// fails is put back with Nack. Publish returns nil when ctx is done or the
+// buffer is closed.
+func (br *Bridge[T]) Publish(ctx context.Context, subject string) error {
+	for {
+		d, err := br.buffer.DequeueDeliveryContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, prb.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		m := nats.NewMsg(subject)
+		m.Header.Set(br.header, strconv.Itoa(d.Element.Priority))
+		m.Data, err = br.codec.AppendValue(nil, d.Element.Value)
+		if err == nil {
+			err = br.publish(m)
+		}
+		if err != nil {
+			br.buffer.Nack(d.ID, 0)
+			return err
+		}
+
+		br.buffer.Ack(d.ID)
+	}
+}
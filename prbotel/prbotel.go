@@ -0,0 +1,40 @@
+// Package prbotel records buffer operations as OpenTelemetry spans.
+package prbotel
+
+import (
+	"context"
+
+	"GoPRB/prb"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a prb.Tracer that emits one span per operation, named
+// "prb.insert" or "prb.dequeue", for use with prb.WithTracer.
+func NewTracer(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+func (t *Tracer) Trace(event prb.TraceEvent) {
+	_, span := t.tracer.Start(context.Background(), "prb."+event.Operation,
+		trace.WithTimestamp(event.Start),
+		trace.WithAttributes(
+			attribute.Int("prb.priority", event.Priority),
+			attribute.Int("prb.size", event.Size),
+			attribute.Bool("prb.overwrite", event.Overwrite),
+		),
+	)
+
+	if event.Err != nil {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+
+	span.End(trace.WithTimestamp(event.End))
+}
@@ -0,0 +1,101 @@
+// Package prbpipe connects buffers into pipelines.
+package prbpipe
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+
+	"GoPRB/prb"
+)
+
+// Transform maps an element of the source buffer to the value and priority
+// it is inserted into the destination with. Returning false drops the
+// element.
+type Transform[A, B comparable] func(e prb.Element[A]) (value B, priority int, ok bool)
+
+// Pipe pumps elements from one buffer to another until it is stopped, the
+// source is closed or the destination fails.
+type Pipe struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Connect starts moving elements from src to dst, passing each through
+// transform. A nil transform requires A and B to be the same type and keeps
+// values and priorities. When dst has no room, the pipe waits for it
+// instead of dropping elements, unless dst's overflow mode evicts to make
+// room.
+func Connect[A, B comparable](src *prb.PriorityRingBuffer[A], dst *prb.PriorityRingBuffer[B], transform Transform[A, B]) *Pipe {
+	if transform == nil {
+		transform = func(e prb.Element[A]) (B, int, bool) {
+			value, ok := any(e.Value).(B)
+			return value, e.Priority, ok
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pipe{cancel: cancel, done: make(chan struct{})}
+	go pprof.Do(ctx, pprof.Labels("prb", "pipe"), func(ctx context.Context) {
+		defer close(p.done)
+		p.err = pump(ctx, src, dst, transform)
+	})
+
+	return p
+}
+
+// Stop stops the pipe and waits for it to exit. It returns the error that
+// ended the pipe, if it ended on its own before.
+func (p *Pipe) Stop() error {
+	p.cancel()
+	<-p.done
+	return p.err
+}
+
+// Done is closed once the pipe has exited.
+func (p *Pipe) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error that ended the pipe: nil if it was stopped or the
+// source was closed, otherwise the destination's insert error. It is only
+// valid once Done is closed.
+func (p *Pipe) Err() error {
+	return p.err
+}
+
+// pump moves elements until ctx is done. An element that cannot be inserted
+// into dst goes back into src.
+func pump[A, B comparable](ctx context.Context, src *prb.PriorityRingBuffer[A], dst *prb.PriorityRingBuffer[B], transform Transform[A, B]) error {
+	for {
+		e, err := src.DequeueContext(ctx)
+		if err != nil {
+			if errors.Is(err, prb.ErrClosed) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		value, priority, ok := transform(e)
+		if !ok {
+			continue
+		}
+
+		if err := dst.InsertContext(ctx, value, priority); err != nil {
+			requeue(src, e)
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func requeue[T comparable](b *prb.PriorityRingBuffer[T], e prb.Element[T]) error {
+	if e.Key != "" {
+		return b.InsertKeyed(e.Key, e.Value, e.Priority)
+	}
+
+	return b.InsertWithMeta(e.Value, e.Priority, e.Tags)
+}
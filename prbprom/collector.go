@@ -0,0 +1,105 @@
+// Package prbprom exports buffer statistics as Prometheus metrics.
+package prbprom
+
+import (
+	"time"
+
+	"GoPRB/prb"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsSource is implemented by *prb.PriorityRingBuffer.
+type StatsSource interface {
+	GetStats() prb.Stats
+}
+
+// Histogram bucket bounds run over the powers of two from about a
+// microsecond to about a second, which line up with the bounds of the
+// buffer's own histograms so the exported counts are exact.
+const (
+	minBoundExp = 10
+	maxBoundExp = 30
+)
+
+// Collector is a prometheus.Collector reporting the size, capacity, counters
+// and, if enabled with prb.WithLatencyHistograms, operation latencies of a
+// buffer. Every metric carries a "buffer" label with the name it was created
+// with.
+type Collector struct {
+	source StatsSource
+
+	size       *prometheus.Desc
+	capacity   *prometheus.Desc
+	inserts    *prometheus.Desc
+	dequeues   *prometheus.Desc
+	overwrites *prometheus.Desc
+	rejections *prometheus.Desc
+	removals   *prometheus.Desc
+	duration   *prometheus.Desc
+}
+
+// NewCollector returns a Collector for source, labelled with name.
+func NewCollector(source StatsSource, name string) *Collector {
+	labels := prometheus.Labels{"buffer": name}
+	desc := func(metric, help string, variable ...string) *prometheus.Desc {
+		return prometheus.NewDesc("prb_"+metric, help, variable, labels)
+	}
+
+	return &Collector{
+		source:     source,
+		size:       desc("size", "Number of queued elements."),
+		capacity:   desc("capacity", "Maximum number of queued elements."),
+		inserts:    desc("inserts_total", "Elements inserted."),
+		dequeues:   desc("dequeues_total", "Elements dequeued."),
+		overwrites: desc("overwrites_total", "Elements evicted to make room for an insert."),
+		rejections: desc("rejections_total", "Inserts rejected, by reason.", "reason"),
+		removals:   desc("removals_total", "Elements removed other than by dequeueing."),
+		duration:   desc("operation_duration_seconds", "Duration of buffer operations.", "operation"),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.size
+	ch <- c.capacity
+	ch <- c.inserts
+	ch <- c.dequeues
+	ch <- c.overwrites
+	ch <- c.rejections
+	ch <- c.removals
+	ch <- c.duration
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.source.GetStats()
+
+	ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(s.Size))
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(s.Capacity))
+	ch <- prometheus.MustNewConstMetric(c.inserts, prometheus.CounterValue, float64(s.Inserts))
+	ch <- prometheus.MustNewConstMetric(c.dequeues, prometheus.CounterValue, float64(s.Dequeues))
+	ch <- prometheus.MustNewConstMetric(c.overwrites, prometheus.CounterValue, float64(s.Overwrites))
+	ch <- prometheus.MustNewConstMetric(c.rejections, prometheus.CounterValue, float64(s.GuardRejections), "overflow")
+	ch <- prometheus.MustNewConstMetric(c.rejections, prometheus.CounterValue, float64(s.QuotaRejections), "quota")
+	ch <- prometheus.MustNewConstMetric(c.rejections, prometheus.CounterValue, float64(s.RateRejections), "rate")
+	ch <- prometheus.MustNewConstMetric(c.removals, prometheus.CounterValue, float64(s.Removals))
+
+	if s.Latency != nil {
+		ch <- c.histogram(s.Latency.Insert, "insert")
+		ch <- c.histogram(s.Latency.Dequeue, "dequeue")
+		ch <- c.histogram(s.Latency.Search, "search")
+	}
+}
+
+func (c *Collector) histogram(h prb.LatencyHistogram, operation string) prometheus.Metric {
+	buckets := make(map[float64]uint64, maxBoundExp-minBoundExp+1)
+	i, cumulative := 0, uint64(0)
+	for exp := minBoundExp; exp <= maxBoundExp; exp++ {
+		bound := time.Duration(1) << exp
+		for ; i < len(h.Buckets) && h.Buckets[i].UpperBound <= bound; i++ {
+			cumulative += h.Buckets[i].Count
+		}
+		buckets[bound.Seconds()] = cumulative
+	}
+
+	return prometheus.MustNewConstHistogram(c.duration, h.Count, h.Sum.Seconds(), buckets, operation)
+}
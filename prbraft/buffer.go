@@ -0,0 +1,114 @@
+package prbraft
+
+import (
+	"maps"
+	"time"
+
+	"GoPRB/prb"
+
+	"github.com/hashicorp/raft"
+)
+
+// Buffer is the cluster-wide view of a replicated buffer. Mutations are
+// committed through the raft log and fail with raft.ErrNotLeader on
+// followers; reads verify leadership first so they observe every committed
+// mutation.
+type Buffer[T comparable] struct {
+	raft    *raft.Raft
+	fsm     *FSM[T]
+	timeout time.Duration
+}
+
+type Option[T comparable] func(*Buffer[T])
+
+// WithApplyTimeout bounds how long a mutation waits to be committed. The
+// default is ten seconds.
+func WithApplyTimeout[T comparable](timeout time.Duration) Option[T] {
+	return func(b *Buffer[T]) {
+		b.timeout = timeout
+	}
+}
+
+// New returns the view of the buffer replicated by r, which must have been
+// created with fsm.
+func New[T comparable](r *raft.Raft, fsm *FSM[T], opts ...Option[T]) *Buffer[T] {
+	b := &Buffer[T]{raft: r, fsm: fsm, timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+func (b *Buffer[T]) Insert(value T, priority int) error {
+	return b.insert(prb.Element[T]{Value: value, Priority: priority})
+}
+
+// InsertKeyed is prb.PriorityRingBuffer.InsertKeyed committed through the
+// cluster.
+func (b *Buffer[T]) InsertKeyed(key string, value T, priority int) error {
+	return b.insert(prb.Element[T]{Value: value, Priority: priority, Key: key})
+}
+
+// InsertWithMeta is prb.PriorityRingBuffer.InsertWithMeta committed through
+// the cluster.
+func (b *Buffer[T]) InsertWithMeta(value T, priority int, tags map[string]string) error {
+	return b.insert(prb.Element[T]{Value: value, Priority: priority, Tags: maps.Clone(tags)})
+}
+
+func (b *Buffer[T]) insert(e prb.Element[T]) error {
+	cmd, err := b.fsm.encodeInsert(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.apply(cmd)
+	return err
+}
+
+func (b *Buffer[T]) Dequeue() (prb.Element[T], error) {
+	return b.apply([]byte{opDequeue})
+}
+
+func (b *Buffer[T]) Clear() error {
+	_, err := b.apply([]byte{opClear})
+	return err
+}
+
+func (b *Buffer[T]) apply(cmd []byte) (prb.Element[T], error) {
+	future := b.raft.Apply(cmd, b.timeout)
+	if err := future.Error(); err != nil {
+		return prb.Element[T]{}, err
+	}
+
+	res := future.Response().(result[T])
+	return res.element, res.err
+}
+
+func (b *Buffer[T]) Peek() (prb.Element[T], error) {
+	if err := b.verify(); err != nil {
+		return prb.Element[T]{}, err
+	}
+
+	return b.fsm.buffer.Peek()
+}
+
+// Len returns the local size, which is current on the leader and may lag
+// on followers.
+func (b *Buffer[T]) Len() int {
+	return b.fsm.buffer.Len()
+}
+
+func (b *Buffer[T]) Cap() int {
+	return b.fsm.buffer.Cap()
+}
+
+// verify confirms this node is still the leader and has applied every
+// committed entry.
+func (b *Buffer[T]) verify() error {
+	if err := b.raft.VerifyLeader().Error(); err != nil {
+		return err
+	}
+
+	return b.raft.Barrier(b.timeout).Error()
+}
@@ -0,0 +1,180 @@
+// Package prbraft replicates a buffer across a hashicorp/raft cluster, so
+// that several nodes present a single strongly consistent buffer.
+package prbraft
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"GoPRB/prb"
+
+	"github.com/hashicorp/raft"
+)
+
+var ErrInvalidCommand = errors.New("invalid raft log command")
+
+const (
+	opInsert byte = iota
+	opDequeue
+	opClear
+)
+
+// FSM applies committed log entries to a local buffer. Every node of the
+// cluster needs one over an identically configured buffer. Apply must be
+// deterministic, so the buffer must not rate limit, decay priorities, adapt
+// its window or time out deliveries, and must only be mutated through the
+// cluster.
+type FSM[T comparable] struct {
+	buffer *prb.PriorityRingBuffer[T]
+	codec  prb.Codec[T]
+}
+
+// result is the response of an applied command.
+type result[T comparable] struct {
+	element prb.Element[T]
+	err     error
+}
+
+// NewFSM wraps b, encoding values in log entries with codec, or
+// prb.DefaultCodec if codec is nil.
+func NewFSM[T comparable](b *prb.PriorityRingBuffer[T], codec prb.Codec[T]) *FSM[T] {
+	if codec == nil {
+		codec = prb.DefaultCodec[T]{}
+	}
+
+	return &FSM[T]{buffer: b, codec: codec}
+}
+
+// Buffer returns the local copy of the replicated buffer.
+func (f *FSM[T]) Buffer() *prb.PriorityRingBuffer[T] {
+	return f.buffer
+}
+
+func (f *FSM[T]) Apply(log *raft.Log) any {
+	data := log.Data
+	if len(data) == 0 {
+		return result[T]{err: ErrInvalidCommand}
+	}
+
+	switch data[0] {
+	case opInsert:
+		e, err := f.decodeInsert(data[1:])
+		if err != nil {
+			return result[T]{err: err}
+		}
+		if e.Key != "" {
+			return result[T]{err: f.buffer.InsertKeyed(e.Key, e.Value, e.Priority)}
+		}
+		return result[T]{err: f.buffer.InsertWithMeta(e.Value, e.Priority, e.Tags)}
+	case opDequeue:
+		e, err := f.buffer.Dequeue()
+		return result[T]{element: e, err: err}
+	case opClear:
+		return result[T]{err: f.buffer.ClearErr()}
+	}
+
+	return result[T]{err: ErrInvalidCommand}
+}
+
+func (f *FSM[T]) Snapshot() (raft.FSMSnapshot, error) {
+	data, err := f.buffer.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot(data), nil
+}
+
+func (f *FSM[T]) Restore(r io.ReadCloser) error {
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return f.buffer.UnmarshalBinary(data)
+}
+
+type snapshot []byte
+
+func (s snapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := sink.Write(s); err != nil {
+		sink.Cancel()
+		return err
+	}
+
+	return sink.Close()
+}
+
+func (s snapshot) Release() {}
+
+// encodeInsert encodes an insert command: priority, key, tags, then the
+// value.
+func (f *FSM[T]) encodeInsert(e prb.Element[T]) ([]byte, error) {
+	cmd := binary.AppendVarint([]byte{opInsert}, int64(e.Priority))
+	cmd = appendString(cmd, e.Key)
+	cmd = binary.AppendUvarint(cmd, uint64(len(e.Tags)))
+	for name, value := range e.Tags {
+		cmd = appendString(appendString(cmd, name), value)
+	}
+
+	return f.codec.AppendValue(cmd, e.Value)
+}
+
+func (f *FSM[T]) decodeInsert(data []byte) (prb.Element[T], error) {
+	var e prb.Element[T]
+	priority, n := binary.Varint(data)
+	if n <= 0 {
+		return e, ErrInvalidCommand
+	}
+	e.Priority = int(priority)
+	data = data[n:]
+
+	var ok bool
+	if e.Key, data, ok = readString(data); !ok {
+		return e, ErrInvalidCommand
+	}
+
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(len(data)) {
+		return e, ErrInvalidCommand
+	}
+	data = data[n:]
+	if count > 0 {
+		e.Tags = make(map[string]string, count)
+	}
+	for range count {
+		var name, value string
+		if name, data, ok = readString(data); !ok {
+			return e, ErrInvalidCommand
+		}
+		if value, data, ok = readString(data); !ok {
+			return e, ErrInvalidCommand
+		}
+		e.Tags[name] = value
+	}
+
+	value, err := f.codec.DecodeValue(data)
+	if err != nil {
+		return e, err
+	}
+	e.Value = value
+
+	return e, nil
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func readString(data []byte) (string, []byte, bool) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return "", nil, false
+	}
+
+	return string(data[n : n+int(length)]), data[n+int(length):], true
+}
@@ -0,0 +1,110 @@
+package prbtest
+
+import (
+	"sync"
+	"time"
+
+	"GoPRB/prb"
+)
+
+// ManualClock is a prb.Clock that only moves when Advance is called. Timers
+// fire synchronously inside Advance.
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) NewTimer(d time.Duration) prb.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTimer{clock: c, c: make(chan time.Time, 1)}
+	t.arm(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that expires on
+// the way in deadline order.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+	for {
+		next := -1
+		for i, t := range c.timers {
+			if !t.deadline.After(end) && (next < 0 || t.deadline.Before(c.timers[next].deadline)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		t := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		c.now = t.deadline
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+	c.now = end
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	c        chan time.Time
+	deadline time.Time
+}
+
+// arm schedules t. The clock lock must be held.
+func (t *manualTimer) arm(d time.Duration) {
+	t.deadline = t.clock.now.Add(d)
+	t.clock.timers = append(t.clock.timers, t)
+}
+
+// disarm unschedules t and reports whether it was pending. The clock lock
+// must be held.
+func (t *manualTimer) disarm() bool {
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.disarm()
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.disarm()
+	t.arm(d)
+	return active
+}
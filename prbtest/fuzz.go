@@ -0,0 +1,119 @@
+package prbtest
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+const (
+	opInsert = iota
+	opDequeue
+	opSearch
+	opCount
+)
+
+// Fuzz registers seeds and a fuzz target that replays the input as a
+// sequence of operations against both a buffer and the Model. Call it from
+// a fuzz test:
+//
+//	func FuzzBuffer(f *testing.F) { prbtest.Fuzz(f) }
+func Fuzz(f *testing.F) {
+	for _, seed := range [][]byte{
+		{0, 0, 1, opInsert, 1, opInsert, 2, opDequeue, opDequeue, opDequeue},
+		{2, 2, 0, opInsert, 1, opInsert, 3, opInsert, 2, opInsert, 5, opSearch, 2, opDequeue},
+		{3, 1, 1, opInsert, 4, opInsert, 4, opInsert, 1, opInsert, 7, opInsert, 4, opInsert, 0, opDequeue, opInsert, 6},
+		{7, 7, 0, opInsert, 0, opInsert, 1, opInsert, 2, opInsert, 3, opInsert, 4, opInsert, 5, opInsert, 6, opInsert, 7, opInsert, 0},
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		CheckOps(t, data)
+	})
+}
+
+// CheckOps decodes data into a buffer configuration and operation sequence,
+// applies it to a prb.PriorityRingBuffer and a Model and fails t at the
+// first diverging result or invariant violation.
+//
+// The first three bytes select capacity, bubble window and overflow mode;
+// the rest is a stream of opcodes, insert and search taking a priority
+// operand from the following byte.
+func CheckOps(t testing.TB, data []byte) {
+	t.Helper()
+
+	if len(data) < 3 {
+		return
+	}
+
+	capacity := int(data[0])%16 + 1
+	window := int(data[1]) % capacity
+	mode := prb.OverflowMode(data[2] % uint8(prb.Block))
+	data = data[3:]
+
+	b, err := prb.New[int](capacity, prb.WithBubbleWindow[int](window), prb.WithOverflowMode[int](mode))
+	if err != nil {
+		t.Fatalf("New(%d, window %d, %v): %v", capacity, window, mode, err)
+	}
+	m := NewModel[int](capacity, window, mode)
+
+	operand := func() int {
+		if len(data) == 0 {
+			return 0
+		}
+		v := int(data[0]) % 8
+		data = data[1:]
+		return v
+	}
+
+	for step := 0; len(data) > 0; step++ {
+		op := data[0] % 4
+		data = data[1:]
+
+		switch op {
+		case opInsert:
+			priority := operand()
+			got, want := b.Insert(step, priority), m.Insert(step, priority)
+			if !errors.Is(got, want) && !errors.Is(want, got) {
+				t.Fatalf("step %d: Insert(%d, %d) = %v, model %v", step, step, priority, got, want)
+			}
+		case opDequeue:
+			got, gotErr := b.Dequeue()
+			want, wantErr := m.Dequeue()
+			got.GuaranteedMax = false
+			if !equalElements(got, want) || !errors.Is(gotErr, wantErr) {
+				t.Fatalf("step %d: Dequeue() = %v, %v, model %v, %v", step, got, gotErr, want, wantErr)
+			}
+		case opSearch:
+			filter := prb.SearchByMinPriority[int](operand())
+			if got, want := b.Search(filter), m.Search(filter); !slices.Equal(got, want) {
+				t.Fatalf("step %d: Search = %v, model %v", step, got, want)
+			}
+		case opCount:
+			filter := prb.SearchByPriority[int](operand())
+			if got, want := b.Count(filter), len(m.Search(filter)); got != want {
+				t.Fatalf("step %d: Count = %d, model %d", step, got, want)
+			}
+		}
+
+		if err := b.Validate(); err != nil {
+			t.Fatalf("step %d: %v", step, err)
+		}
+
+		got := b.Snapshot()
+		for i := range got {
+			got[i].GuaranteedMax = false
+		}
+		if want := m.Snapshot(); !slices.EqualFunc(got, want, equalElements) {
+			t.Fatalf("step %d: contents %v, model %v", step, got, want)
+		}
+	}
+}
+
+func equalElements(x, y prb.Element[int]) bool {
+	return reflect.DeepEqual(x, y)
+}
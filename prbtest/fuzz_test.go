@@ -0,0 +1,13 @@
+package prbtest_test
+
+import (
+	"testing"
+
+	"GoPRB/prbtest"
+)
+
+// FuzzBuffer runs the seeds registered by prbtest.Fuzz and the corpus in
+// testdata/fuzz/FuzzBuffer; use go test -fuzz=FuzzBuffer to explore more.
+func FuzzBuffer(f *testing.F) {
+	prbtest.Fuzz(f)
+}
@@ -0,0 +1,121 @@
+// Package prbtest provides helpers for testing code built on prb: a
+// slice-based reference model of the buffer, a fuzz harness diffing the two
+// and a concurrent stress test.
+package prbtest
+
+import (
+	"slices"
+
+	"GoPRB/prb"
+)
+
+// Model is a deliberately simple reference implementation of the insert,
+// dequeue and search semantics of prb.PriorityRingBuffer, kept in a plain
+// slice in dequeue order. It does not model prb.Block.
+type Model[T comparable] struct {
+	capacity     int
+	bubbleWindow int
+	overflow     prb.OverflowMode
+	orderCounter int64
+	elements     []prb.Element[T]
+}
+
+func NewModel[T comparable](capacity, bubbleWindow int, overflow prb.OverflowMode) *Model[T] {
+	return &Model[T]{
+		capacity:     capacity,
+		bubbleWindow: bubbleWindow,
+		overflow:     overflow,
+	}
+}
+
+// Insert appends the element, evicting as the overflow mode dictates when
+// full, and moves it towards the head past at most bubbleWindow lower ranked
+// elements.
+func (m *Model[T]) Insert(value T, priority int) error {
+	element := prb.Element[T]{
+		Value:          value,
+		Priority:       priority,
+		InsertionOrder: m.orderCounter,
+	}
+	m.orderCounter++
+
+	if len(m.elements) == m.capacity {
+		victim := 0
+		switch m.overflow {
+		case prb.Reject:
+			if priority <= m.elements[0].Priority {
+				return prb.ErrBufferFull
+			}
+		case prb.DropNewest, prb.Block:
+			return prb.ErrBufferFull
+		case prb.DropLowestPriority:
+			for i, e := range m.elements {
+				lowest := m.elements[victim]
+				if e.Priority < lowest.Priority || (e.Priority == lowest.Priority && e.InsertionOrder < lowest.InsertionOrder) {
+					victim = i
+				}
+			}
+			if priority < m.elements[victim].Priority {
+				return prb.ErrBufferFull
+			}
+		}
+		m.elements = slices.Delete(m.elements, victim, victim+1)
+	}
+
+	i := len(m.elements)
+	m.elements = append(m.elements, element)
+	for steps := 0; steps < m.bubbleWindow && i > 0 && outranks(m.elements[i], m.elements[i-1]); steps++ {
+		m.elements[i], m.elements[i-1] = m.elements[i-1], m.elements[i]
+		i--
+	}
+
+	return nil
+}
+
+func (m *Model[T]) Dequeue() (prb.Element[T], error) {
+	if len(m.elements) == 0 {
+		return prb.Element[T]{}, prb.ErrBufferEmpty
+	}
+
+	element := m.elements[0]
+	m.elements = m.elements[1:]
+	return element, nil
+}
+
+func (m *Model[T]) Search(filters ...prb.SearchFilter[T]) []int {
+	var result []int
+	for i, e := range m.elements {
+		if matches(e, filters) {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+func (m *Model[T]) Snapshot() []prb.Element[T] {
+	if len(m.elements) == 0 {
+		return nil
+	}
+
+	return append([]prb.Element[T](nil), m.elements...)
+}
+
+func (m *Model[T]) Len() int {
+	return len(m.elements)
+}
+
+func outranks[T comparable](a, b prb.Element[T]) bool {
+	return a.Priority > b.Priority ||
+		(a.Priority == b.Priority && a.InsertionOrder < b.InsertionOrder)
+}
+
+func matches[T comparable](e prb.Element[T], filters []prb.SearchFilter[T]) bool {
+	for _, filter := range filters {
+		if !filter(e) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,143 @@
+package prbtest
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"GoPRB/prb"
+)
+
+// StressConfig controls the load generated by Stress.
+type StressConfig struct {
+	Producers int
+	Consumers int
+	// Inserts is the number of inserts each producer performs.
+	Inserts int
+	// Priorities is the number of distinct priorities inserted.
+	Priorities int
+	// Lossy allows accepted elements to vanish, as happens when a buffer
+	// without overwrite guard evicts its head. Duplicated or fabricated
+	// elements are reported either way.
+	Lossy bool
+}
+
+const (
+	statePending int32 = iota
+	stateInserted
+	stateDequeued
+	stateRejected
+)
+
+// Stress hammers b from cfg.Producers inserting and cfg.Consumers
+// dequeuing goroutines, then drains it and reports to t any element that
+// was dequeued twice, dequeued without being accepted, came back with a
+// different priority, or (unless cfg.Lossy) was lost, as well as any Len
+// outside [0, Cap]. b must be empty.
+func Stress(t testing.TB, b prb.PriorityBuffer[int], cfg StressConfig) {
+	t.Helper()
+
+	cfg.Producers = max(cfg.Producers, 1)
+	cfg.Consumers = max(cfg.Consumers, 1)
+	cfg.Priorities = max(cfg.Priorities, 1)
+
+	total := cfg.Producers * cfg.Inserts
+	states := make([]atomic.Int32, total)
+
+	checkLen := func() {
+		if n := b.Len(); n < 0 || n > b.Cap() {
+			t.Errorf("Len() = %d outside [0, %d]", n, b.Cap())
+		}
+	}
+
+	receive := func(e prb.Element[int]) {
+		if e.Value < 0 || e.Value >= total {
+			t.Errorf("dequeued unknown value %d", e.Value)
+			return
+		}
+		if want := e.Value % cfg.Priorities; e.Priority != want {
+			t.Errorf("value %d dequeued with priority %d, inserted with %d", e.Value, e.Priority, want)
+		}
+
+		switch state := states[e.Value].Swap(stateDequeued); state {
+		case stateDequeued:
+			t.Errorf("value %d dequeued twice", e.Value)
+		case statePending, stateRejected:
+			t.Errorf("value %d dequeued but never accepted", e.Value)
+		}
+	}
+
+	var producers, consumers sync.WaitGroup
+	var done atomic.Bool
+
+	for p := range cfg.Producers {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for i := range cfg.Inserts {
+				value := p*cfg.Inserts + i
+				states[value].Store(stateInserted)
+
+				err := b.Insert(value, value%cfg.Priorities)
+				if errors.Is(err, prb.ErrBufferFull) || errors.Is(err, prb.ErrQuotaExceeded) {
+					states[value].CompareAndSwap(stateInserted, stateRejected)
+				} else if err != nil {
+					t.Errorf("Insert(%d): %v", value, err)
+					states[value].CompareAndSwap(stateInserted, stateRejected)
+				}
+				checkLen()
+			}
+		}()
+	}
+
+	for range cfg.Consumers {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				e, err := b.Dequeue()
+				switch {
+				case err == nil:
+					receive(e)
+				case errors.Is(err, prb.ErrBufferEmpty):
+					if done.Load() {
+						return
+					}
+					runtime.Gosched()
+				default:
+					t.Errorf("Dequeue: %v", err)
+					return
+				}
+				checkLen()
+			}
+		}()
+	}
+
+	producers.Wait()
+	done.Store(true)
+	consumers.Wait()
+
+	for {
+		e, err := b.Dequeue()
+		if err != nil {
+			break
+		}
+		receive(e)
+	}
+
+	if b.Len() != 0 {
+		t.Errorf("Len() = %d after draining", b.Len())
+	}
+
+	if cfg.Lossy {
+		return
+	}
+
+	for value := range states {
+		if states[value].Load() == stateInserted {
+			t.Errorf("value %d accepted but never dequeued", value)
+		}
+	}
+}
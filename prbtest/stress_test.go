@@ -0,0 +1,64 @@
+package prbtest_test
+
+import (
+	"testing"
+
+	"GoPRB/prb"
+	"GoPRB/prbtest"
+)
+
+// TestStress is meant to run under go test -race.
+func TestStress(t *testing.T) {
+	inserts := 2000
+	if testing.Short() {
+		inserts = 200
+	}
+
+	tests := []struct {
+		name   string
+		buffer func() (prb.PriorityBuffer[int], error)
+		lossy  bool
+	}{
+		{"reject", func() (prb.PriorityBuffer[int], error) {
+			return prb.New[int](64, prb.WithOverflowMode[int](prb.Reject))
+		}, true},
+		{"drop-newest", func() (prb.PriorityBuffer[int], error) {
+			return prb.New[int](64, prb.WithOverflowMode[int](prb.DropNewest))
+		}, false},
+		{"drop-oldest", func() (prb.PriorityBuffer[int], error) {
+			return prb.New[int](64, prb.WithBubbleWindow[int](8))
+		}, true},
+		{"drop-lowest", func() (prb.PriorityBuffer[int], error) {
+			return prb.New[int](64, prb.WithOverflowMode[int](prb.DropLowestPriority))
+		}, true},
+		{"block", func() (prb.PriorityBuffer[int], error) {
+			return prb.New[int](16, prb.WithOverflowMode[int](prb.Block))
+		}, false},
+		{"fully-ordered", func() (prb.PriorityBuffer[int], error) {
+			return prb.New[int](64, prb.WithBubbleWindow[int](63), prb.WithOverflowMode[int](prb.DropNewest))
+		}, false},
+		{"sharded", func() (prb.PriorityBuffer[int], error) {
+			return prb.NewSharded[int](4, 16, prb.WithShardOptions[int](prb.WithOverflowMode[int](prb.DropNewest)))
+		}, false},
+		{"tiered", func() (prb.PriorityBuffer[int], error) {
+			return prb.NewTiered[int](8, 64, prb.WithColdOptions[int](prb.WithOverflowMode[int](prb.DropNewest)))
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.buffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prbtest.Stress(t, b, prbtest.StressConfig{
+				Producers:  4,
+				Consumers:  4,
+				Inserts:    inserts,
+				Priorities: 8,
+				Lossy:      tt.lossy,
+			})
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package prbwork
+
+import (
+	"context"
+	"runtime/pprof"
+	"slices"
+	"sync"
+
+	"GoPRB/prb"
+)
+
+// Broadcaster dequeues from a source buffer and inserts every element into
+// each subscriber's own buffer, so independent consumers see the same
+// prioritized stream. What a full subscriber does with a new element is up to
+// its overflow mode; a Block subscriber holds up delivery to everyone.
+type Broadcaster[T comparable] struct {
+	source  *prb.PriorityRingBuffer[T]
+	onError func(*prb.PriorityRingBuffer[T], prb.Element[T], error)
+
+	mu          sync.Mutex
+	subscribers []*prb.PriorityRingBuffer[T]
+	groups      map[string]*ConsumerGroup[T]
+	subscribed  chan struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type BroadcastOption[T comparable] func(*Broadcaster[T])
+
+// OnBroadcastError is called when an element cannot be inserted into a
+// subscriber, for example because it is full and rejects it.
+func OnBroadcastError[T comparable](fn func(sub *prb.PriorityRingBuffer[T], e prb.Element[T], err error)) BroadcastOption[T] {
+	return func(br *Broadcaster[T]) {
+		br.onError = fn
+	}
+}
+
+// NewBroadcaster starts delivering elements from source. Nothing is dequeued
+// while there are no subscribers. Delivery stops when source is closed or
+// the broadcaster is stopped.
+func NewBroadcaster[T comparable](source *prb.PriorityRingBuffer[T], opts ...BroadcastOption[T]) *Broadcaster[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	br := &Broadcaster[T]{
+		source:     source,
+		subscribed: make(chan struct{}, 1),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(br)
+	}
+
+	go pprof.Do(ctx, pprof.Labels("prb", "broadcaster"), br.run)
+	return br
+}
+
+// Subscribe creates a buffer with the given capacity and options that
+// receives every element dequeued from the source from now on.
+func (br *Broadcaster[T]) Subscribe(capacity int, opts ...prb.Option[T]) (*prb.PriorityRingBuffer[T], error) {
+	sub, err := prb.New(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	br.mu.Lock()
+	br.subscribers = append(br.subscribers, sub)
+	br.mu.Unlock()
+
+	select {
+	case br.subscribed <- struct{}{}:
+	default:
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe stops delivery to sub. The buffer and its elements are left to
+// the caller.
+func (br *Broadcaster[T]) Unsubscribe(sub *prb.PriorityRingBuffer[T]) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	br.unsubscribe(sub)
+}
+
+// unsubscribe stops delivery to sub. The caller must hold br.mu.
+func (br *Broadcaster[T]) unsubscribe(sub *prb.PriorityRingBuffer[T]) {
+	br.subscribers = slices.DeleteFunc(br.subscribers, func(s *prb.PriorityRingBuffer[T]) bool {
+		return s == sub
+	})
+}
+
+// Stop stops delivery and waits for the element in flight, if any, to reach
+// the subscribers.
+func (br *Broadcaster[T]) Stop() {
+	br.cancel()
+	<-br.done
+}
+
+func (br *Broadcaster[T]) run(ctx context.Context) {
+	defer close(br.done)
+
+	for {
+		br.mu.Lock()
+		waiting := len(br.subscribers) == 0
+		br.mu.Unlock()
+
+		if waiting {
+			select {
+			case <-br.subscribed:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		e, err := br.source.DequeueContext(ctx)
+		if err != nil {
+			return
+		}
+
+		br.mu.Lock()
+		subscribers := slices.Clone(br.subscribers)
+		br.mu.Unlock()
+
+		for _, sub := range subscribers {
+			if err := deliver(sub, e); err != nil && br.onError != nil {
+				br.onError(sub, e, err)
+			}
+		}
+	}
+}
+
+// deliver inserts e into b, keeping its key and tags.
+func deliver[T comparable](b *prb.PriorityRingBuffer[T], e prb.Element[T]) error {
+	if e.Key != "" {
+		return b.InsertKeyed(e.Key, e.Value, e.Priority)
+	}
+
+	return b.InsertWithMeta(e.Value, e.Priority, e.Tags)
+}
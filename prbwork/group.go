@@ -0,0 +1,146 @@
+package prbwork
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"GoPRB/prb"
+)
+
+var ErrLeftGroup = errors.New("consumer has left its group")
+
+// ConsumerGroup shares one subscription of a Broadcaster among its members:
+// each element delivered to the group is received by exactly one member,
+// while every group sees the whole stream. Elements are kept while the group
+// has no members.
+type ConsumerGroup[T comparable] struct {
+	name   string
+	buffer *prb.PriorityRingBuffer[T]
+
+	mu      sync.Mutex
+	members map[*Consumer[T]]struct{}
+}
+
+// Consumer is one member of a ConsumerGroup.
+type Consumer[T comparable] struct {
+	group *ConsumerGroup[T]
+	left  chan struct{}
+	once  sync.Once
+}
+
+// Group returns the consumer group called name, subscribing a new one with
+// the given buffer capacity and options if it does not exist yet.
+func (br *Broadcaster[T]) Group(name string, capacity int, opts ...prb.Option[T]) (*ConsumerGroup[T], error) {
+	br.mu.Lock()
+	g, ok := br.groups[name]
+	br.mu.Unlock()
+	if ok {
+		return g, nil
+	}
+
+	sub, err := br.Subscribe(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	if g, ok := br.groups[name]; ok {
+		br.unsubscribe(sub)
+		return g, nil
+	}
+
+	g = &ConsumerGroup[T]{
+		name:    name,
+		buffer:  sub,
+		members: make(map[*Consumer[T]]struct{}),
+	}
+	if br.groups == nil {
+		br.groups = make(map[string]*ConsumerGroup[T])
+	}
+	br.groups[name] = g
+	return g, nil
+}
+
+// RemoveGroup stops delivery to the group called name and returns its
+// buffer with any undelivered elements, or nil if there is no such group.
+func (br *Broadcaster[T]) RemoveGroup(name string) *prb.PriorityRingBuffer[T] {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	g, ok := br.groups[name]
+	if !ok {
+		return nil
+	}
+
+	delete(br.groups, name)
+	br.unsubscribe(g.buffer)
+	return g.buffer
+}
+
+func (g *ConsumerGroup[T]) Name() string {
+	return g.name
+}
+
+// Buffer returns the group's subscription buffer, for example to consume it
+// with a Pool.
+func (g *ConsumerGroup[T]) Buffer() *prb.PriorityRingBuffer[T] {
+	return g.buffer
+}
+
+// Members returns the number of consumers that have joined and not left.
+func (g *ConsumerGroup[T]) Members() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return len(g.members)
+}
+
+// Join adds a consumer to the group.
+func (g *ConsumerGroup[T]) Join() *Consumer[T] {
+	c := &Consumer[T]{group: g, left: make(chan struct{})}
+
+	g.mu.Lock()
+	g.members[c] = struct{}{}
+	g.mu.Unlock()
+
+	return c
+}
+
+// Receive waits for the next element delivered to the group that no other
+// member has received. It returns ErrLeftGroup once the consumer has left.
+func (c *Consumer[T]) Receive(ctx context.Context) (prb.Element[T], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.left:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	e, err := c.group.buffer.DequeueContext(ctx)
+	if err != nil {
+		select {
+		case <-c.left:
+			return prb.Element[T]{}, ErrLeftGroup
+		default:
+		}
+	}
+	return e, err
+}
+
+// Leave removes the consumer from the group and wakes a pending Receive.
+func (c *Consumer[T]) Leave() {
+	c.once.Do(func() {
+		close(c.left)
+
+		c.group.mu.Lock()
+		delete(c.group.members, c)
+		c.group.mu.Unlock()
+	})
+}
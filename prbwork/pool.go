@@ -0,0 +1,209 @@
+// Package prbwork consumes buffers with pools of workers.
+package prbwork
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"runtime/pprof"
+	"sync"
+
+	"GoPRB/prb"
+)
+
+var ErrInvalidPool = errors.New("pool needs a handler and positive worker and concurrency counts")
+
+// Handler processes one dequeued element. ctx is cancelled when the pool is
+// stopped without draining.
+type Handler[T comparable] func(ctx context.Context, e prb.Element[T]) error
+
+// PanicError is reported for an element whose handler panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("prbwork: handler panicked: %v", e.Value)
+}
+
+// Pool dequeues elements from a buffer with a fixed number of workers, each
+// running up to a limited number of handlers at once.
+type Pool[T comparable] struct {
+	buffer      *prb.PriorityRingBuffer[T]
+	handler     Handler[T]
+	workers     int
+	concurrency int
+	onError     func(prb.Element[T], error)
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waitCtx context.Context
+	drain   context.CancelFunc
+	wg      sync.WaitGroup
+	done    chan struct{}
+}
+
+type Option[T comparable] func(*Pool[T])
+
+// WithWorkers sets how many workers dequeue from the buffer. The default is
+// one.
+func WithWorkers[T comparable](n int) Option[T] {
+	return func(p *Pool[T]) {
+		p.workers = n
+	}
+}
+
+// WithConcurrency sets how many handlers each worker may run at once. The
+// default is one, so handlers run on the worker goroutine.
+func WithConcurrency[T comparable](n int) Option[T] {
+	return func(p *Pool[T]) {
+		p.concurrency = n
+	}
+}
+
+// OnError is called with every error a handler returns, a *PanicError for a
+// handler that panicked, and dequeue errors other than an empty or closed
+// buffer, which stop the worker. It may be called concurrently.
+func OnError[T comparable](fn func(prb.Element[T], error)) Option[T] {
+	return func(p *Pool[T]) {
+		p.onError = fn
+	}
+}
+
+// NewPool starts consuming b, calling handler for every dequeued element.
+// Workers exit when the buffer is closed or the pool is shut down.
+func NewPool[T comparable](b *prb.PriorityRingBuffer[T], handler Handler[T], opts ...Option[T]) (*Pool[T], error) {
+	p := &Pool[T]{
+		buffer:      b,
+		handler:     handler,
+		workers:     1,
+		concurrency: 1,
+		done:        make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if handler == nil || p.workers < 1 || p.concurrency < 1 {
+		return nil, ErrInvalidPool
+	}
+
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.waitCtx, p.drain = context.WithCancel(p.ctx)
+
+	p.wg.Add(p.workers)
+	for range p.workers {
+		go pprof.Do(p.ctx, pprof.Labels("prb", "pool-worker"), func(context.Context) {
+			p.work()
+		})
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// Shutdown stops waiting for new elements, lets the workers drain what is
+// left in the buffer and waits for every handler to return. If ctx is done
+// first, handler contexts are cancelled, no further elements are dequeued
+// and ctx.Err() is returned without waiting for the handlers.
+func (p *Pool[T]) Shutdown(ctx context.Context) error {
+	p.drain()
+
+	select {
+	case <-p.done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Stop stops dequeuing immediately, leaving remaining elements in the
+// buffer, cancels handler contexts and waits for the handlers to return.
+func (p *Pool[T]) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+// Done is closed once every worker and handler has returned.
+func (p *Pool[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *Pool[T]) work() {
+	defer p.wg.Done()
+
+	slots := make(chan struct{}, p.concurrency)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		slots <- struct{}{}
+
+		e, ok := p.next()
+		if !ok {
+			return
+		}
+
+		if p.concurrency == 1 {
+			p.handle(e)
+			<-slots
+			continue
+		}
+
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			defer func() { <-slots }()
+			p.handle(e)
+		}()
+	}
+}
+
+// next dequeues the next element, waiting for one unless the pool is
+// draining. It reports false once the worker should exit.
+func (p *Pool[T]) next() (prb.Element[T], bool) {
+	if p.ctx.Err() != nil {
+		return prb.Element[T]{}, false
+	}
+
+	e, err := p.buffer.DequeueContext(p.waitCtx)
+	if err != nil && p.waitCtx.Err() != nil && p.ctx.Err() == nil {
+		e, err = p.buffer.Dequeue()
+	}
+
+	switch {
+	case err == nil:
+		return e, true
+	case errors.Is(err, prb.ErrBufferEmpty), errors.Is(err, prb.ErrClosed), p.ctx.Err() != nil:
+		return prb.Element[T]{}, false
+	}
+
+	p.report(prb.Element[T]{}, err)
+	return prb.Element[T]{}, false
+}
+
+func (p *Pool[T]) handle(e prb.Element[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.report(e, &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+
+	if err := p.handler(p.ctx, e); err != nil {
+		p.report(e, err)
+	}
+}
+
+func (p *Pool[T]) report(e prb.Element[T], err error) {
+	if p.onError != nil {
+		p.onError(e, err)
+	}
+}
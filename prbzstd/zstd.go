@@ -0,0 +1,37 @@
+// Package prbzstd provides a zstd prb.Compressor. Importing it registers the
+// compressor, so buffers can read zstd compressed snapshots and logs without
+// being configured with it.
+package prbzstd
+
+import (
+	"GoPRB/prb"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// Compressor compresses with zstd at the default level, for use with
+// prb.WithCompression.
+var Compressor prb.Compressor = compressor{}
+
+func init() {
+	prb.RegisterCompressor(Compressor)
+}
+
+type compressor struct{}
+
+func (compressor) ID() byte {
+	return prb.CompressorZstd
+}
+
+func (compressor) Compress(dst, src []byte) ([]byte, error) {
+	return encoder.EncodeAll(src, dst), nil
+}
+
+func (compressor) Decompress(src []byte) ([]byte, error) {
+	return decoder.DecodeAll(src, nil)
+}
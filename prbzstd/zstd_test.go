@@ -0,0 +1,87 @@
+package prbzstd_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GoPRB/prb"
+	"GoPRB/prbzstd"
+)
+
+func TestCompressor(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short", []byte("a")},
+		{"repetitive", bytes.Repeat([]byte("prb"), 1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := prbzstd.Compressor.Compress([]byte("prefix"), tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(compressed, []byte("prefix")) {
+				t.Fatalf("Compress did not append to dst")
+			}
+
+			got, err := prbzstd.Compressor.Decompress(compressed[len("prefix"):])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Fatalf("Decompress = %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestBufferRoundTrip(t *testing.T) {
+	value := strings.Repeat("z", 1000)
+	opt := prb.WithCompression[string](prbzstd.Compressor)
+
+	source := prb.MustNew[string](2, opt)
+	path := filepath.Join(t.TempDir(), "wal")
+	logged, err := prb.OpenFromWAL[string](path, 2, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*prb.PriorityRingBuffer[string]{source, logged} {
+		if err := b.Insert(value, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logged.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := source.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(value) {
+		t.Fatalf("snapshot is %d bytes, want it compressed", len(data))
+	}
+
+	// Importing the package registers the compressor for other buffers.
+	b := prb.MustNew[string](2)
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if e, err := b.Peek(); err != nil || e.Value != value {
+		t.Fatalf("Peek() = %.10q, %v, want the inserted value", e.Value, err)
+	}
+
+	replayed, err := prb.ReplayWAL[string](path, 2)
+	if err != nil {
+		t.Fatalf("ReplayWAL: %v", err)
+	}
+	if e, err := replayed.Peek(); err != nil || e.Value != value {
+		t.Fatalf("Peek() = %.10q, %v, want the inserted value", e.Value, err)
+	}
+}